@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"log"
@@ -14,6 +16,7 @@ import (
 	"janus/internal/balancer"
 	"janus/internal/config"
 	"janus/internal/domain"
+	"janus/internal/metrics"
 	"janus/internal/server"
 )
 
@@ -32,9 +35,10 @@ func main() {
 	log.Printf("[INFO] Configuration loaded: port=%d, strategy=%s, servers=%d, health_check=%ds",
 		cfg.Port, cfg.Strategy, len(cfg.Servers), cfg.HealthCheckTime)
 
-	pool := createServerPool(cfg)
+	registry := metrics.NewRegistry()
+	pool, probes := createServerPool(cfg, registry)
 
-	strategy, err := balancer.NewStrategy(cfg.Strategy)
+	strategy, err := createStrategy(cfg)
 	if err != nil {
 		log.Fatalf("[FATAL] Failed to create strategy: %v", err)
 	}
@@ -45,10 +49,36 @@ func main() {
 	defer cancel()
 
 	healthChecker := server.NewHealthChecker(pool, time.Duration(cfg.HealthCheckTime)*time.Second)
-	healthChecker.Start(ctx)
+	healthChecker.SetThresholds(cfg.HealthyThreshold, cfg.UnhealthyThreshold)
+	healthChecker.SetJitter(*cfg.HealthCheckJitter)
+	healthChecker.SetConcurrency(cfg.HealthCheckConcurrency)
+	healthChecker.Instrument(registry)
+	for srv, probe := range probes {
+		healthChecker.SetProbe(srv, probe)
+	}
 
 	proxyHandler := server.NewProxyHandler(pool, strategy)
 
+	if outlier := cfg.OutlierDetection; outlier != nil {
+		detector := server.NewOutlierDetector(pool, outlier.Config())
+		detector.Instrument(registry)
+		proxyHandler.SetOutlierDetector(detector)
+		healthChecker.SetOutlierDetector(detector)
+		log.Printf("[INFO] Outlier detection enabled (consecutive 5xx: %d, base ejection: %ds, max ejected: %.0f%%)",
+			outlier.Consecutive5xx, outlier.BaseEjectionTime, outlier.MaxEjectionPercent)
+	}
+
+	healthChecker.Start(ctx)
+
+	if sticky := cfg.StickySession; sticky != nil {
+		proxyHandler.SetStickySessions(server.NewStickySessions(
+			sticky.CookieName,
+			time.Duration(sticky.TTL)*time.Second,
+			[]byte(sticky.SigningKey),
+		))
+		log.Printf("[INFO] Sticky sessions enabled (cookie: %s, ttl: %ds)", sticky.CookieName, sticky.TTL)
+	}
+
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		Handler:      proxyHandler,
@@ -57,6 +87,28 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	servers := []*http.Server{httpServer}
+
+	if cfg.AdminPort != 0 {
+		admin := server.NewAdminHandler(pool)
+		admin.Handle("GET /metrics", registry)
+
+		adminServer := &http.Server{
+			Addr:         fmt.Sprintf(":%d", cfg.AdminPort),
+			Handler:      admin,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		servers = append(servers, adminServer)
+
+		go func() {
+			log.Printf("[INFO] Admin server listening on :%d", cfg.AdminPort)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("[FATAL] Admin server error: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		log.Printf("[INFO] Proxy server listening on :%d", cfg.Port)
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -64,11 +116,46 @@ func main() {
 		}
 	}()
 
-	gracefulShutdown(httpServer, cancel)
+	gracefulShutdown(cancel, servers...)
 }
 
-func createServerPool(cfg *config.Config) *domain.ServerPool {
+func createServerPool(cfg *config.Config, registry *metrics.Registry) (*domain.ServerPool, map[*domain.Server]server.Probe) {
 	pool := domain.NewServerPool()
+	pool.SetFailoverThreshold(cfg.FailoverThreshold)
+
+	panicMode := registry.Gauge("janus_panic_mode", "Whether the pool ignores health status because too few servers are healthy.")
+	panicEntered := registry.Counter("janus_panic_mode_entered_total", "Number of times the pool entered panic mode.")
+
+	pool.OnPanicChange(func(panicking bool) {
+		if panicking {
+			log.Printf("[WARN] Entering panic mode: fewer than %.0f%% of servers are healthy, routing to all servers",
+				cfg.PanicThreshold*100)
+			panicMode.Set(1)
+			panicEntered.Inc()
+			return
+		}
+
+		log.Println("[INFO] Leaving panic mode: routing to healthy servers only")
+		panicMode.Set(0)
+	})
+	pool.SetPanicThreshold(cfg.PanicThreshold)
+
+	pool.OnBreakerChange(func(srv *domain.Server, from, to domain.BreakerState) {
+		level := "[WARN]"
+		if to == domain.BreakerClosed {
+			level = "[INFO]"
+		}
+		log.Printf("%s Circuit breaker for %s: %s -> %s", level, srv.URL, from, to)
+	})
+
+	registry.GaugeFunc("janus_servers", "Number of configured servers.", func() float64 {
+		return float64(pool.Size())
+	})
+	registry.GaugeFunc("janus_servers_healthy", "Number of servers passing health checks.", func() float64 {
+		return float64(pool.HealthyCount())
+	})
+
+	probes := make(map[*domain.Server]server.Probe)
 
 	for _, serverCfg := range cfg.Servers {
 		srv, err := domain.NewServer(serverCfg.URL, serverCfg.Weight)
@@ -77,18 +164,115 @@ func createServerPool(cfg *config.Config) *domain.ServerPool {
 			continue
 		}
 
+		srv.Priority = serverCfg.Priority
+		srv.Zone = serverCfg.Zone
+		srv.Region = serverCfg.Region
+		srv.HealthCheckAddress = serverCfg.HealthCheck.TargetAddress(srv.URL)
+		srv.SetLatencyDecay(time.Duration(cfg.LatencyDecayTime) * time.Second)
+
+		if slow := cfg.SlowStart; slow != nil {
+			srv.SetSlowStart(time.Duration(slow.Window)*time.Second, slow.Aggression, slow.MinWeightPercent)
+		}
+
+		if breaker := cfg.CircuitBreaker; breaker != nil {
+			srv.SetCircuitBreaker(breaker.Config())
+
+			registry.GaugeFunc("janus_circuit_breaker_state", "Circuit breaker state per server: 0 closed, 1 open, 2 half-open.",
+				func() float64 { return float64(srv.BreakerState()) }, "server", serverCfg.URL)
+			registry.CounterFunc("janus_circuit_breaker_trips_total", "Number of times the circuit breaker opened.",
+				func() float64 { return float64(srv.BreakerTrips()) }, "server", serverCfg.URL)
+		}
+
+		probe, err := createProbe(serverCfg.HealthCheck)
+		if err != nil {
+			log.Fatalf("[FATAL] Invalid health check for %s: %v", serverCfg.URL, err)
+		}
+		probes[srv] = probe
+
+		checkTarget := srv.URL.Host
+		if srv.HealthCheckAddress != "" {
+			checkTarget = srv.HealthCheckAddress
+		}
+
 		pool.AddServer(srv)
-		log.Printf("[INFO] Added server: %s (weight: %d)", serverCfg.URL, serverCfg.Weight)
+		log.Printf("[INFO] Added server: %s (weight: %d, priority: %d, health check: %s on %s)",
+			serverCfg.URL, serverCfg.Weight, serverCfg.Priority, serverCfg.HealthCheck.Type, checkTarget)
 	}
 
 	if pool.Size() == 0 {
 		log.Fatal("[FATAL] No valid servers configured")
 	}
 
-	return pool
+	return pool, probes
 }
 
-func gracefulShutdown(srv *http.Server, cancel context.CancelFunc) {
+func createProbe(check *config.HealthCheck) (server.Probe, error) {
+	timeout := time.Duration(check.Timeout) * time.Second
+
+	var rootCAs *x509.CertPool
+	if check.CAFile != "" {
+		pool, err := server.LoadCertPool(check.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ca_file: %w", err)
+		}
+		rootCAs = pool
+	}
+
+	switch check.Type {
+	case config.HealthCheckHTTP:
+		return server.NewHTTPProbe(check.HTTPProbeConfig())
+	case config.HealthCheckGRPC:
+		var tlsConfig *tls.Config
+		if rootCAs != nil || check.ServerName != "" {
+			tlsConfig = &tls.Config{RootCAs: rootCAs, ServerName: check.ServerName}
+		}
+
+		return server.NewGRPCProbe(server.GRPCProbeConfig{
+			Service:   check.Service,
+			Timeout:   timeout,
+			TLSConfig: tlsConfig,
+		}), nil
+	case config.HealthCheckTLS:
+		return server.NewTLSProbe(server.TLSProbeConfig{
+			ServerName:    check.ServerName,
+			RootCAs:       rootCAs,
+			ExpiryWarning: time.Duration(check.CertExpiryWarning) * 24 * time.Hour,
+			ExpiryFailure: time.Duration(check.CertExpiryFailure) * 24 * time.Hour,
+			Timeout:       timeout,
+		}), nil
+	case config.HealthCheckExec:
+		return server.NewExecProbe(server.ExecProbeConfig{
+			Command: check.Command,
+			Timeout: timeout,
+		})
+	default:
+		return server.NewTCPProbe(timeout), nil
+	}
+}
+
+func createStrategy(cfg *config.Config) (balancer.Strategy, error) {
+	newStrategy := func() (balancer.Strategy, error) {
+		return balancer.NewStrategyWithOptions(cfg.Strategy, cfg.StrategyOptions)
+	}
+
+	locality := cfg.Locality
+	if locality == nil {
+		return newStrategy()
+	}
+
+	log.Printf("[INFO] Locality routing enabled (zone: %s, region: %s, overprovisioning: %.2f)",
+		locality.Zone, locality.Region, locality.OverprovisioningFactor)
+
+	strategy, err := balancer.NewLocality(locality.Zone, locality.Region, locality.OverprovisioningFactor, newStrategy)
+	if err != nil {
+		return nil, err
+	}
+	strategy.SetCapacity(locality.ConnectionsPerWeight)
+
+	return strategy, nil
+}
+
+func gracefulShutdown(cancel context.CancelFunc, servers ...*http.Server) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -100,8 +284,10 @@ func gracefulShutdown(srv *http.Server, cancel context.CancelFunc) {
 	ctx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("[ERROR] Server shutdown error: %v", err)
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("[ERROR] Server shutdown error: %v", err)
+		}
 	}
 
 	log.Println("[INFO] Server stopped gracefully")
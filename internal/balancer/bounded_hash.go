@@ -0,0 +1,99 @@
+package balancer
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+const DefaultBoundedLoadEpsilon = 0.25
+
+func init() {
+	Register("bounded_hash", DefaultBoundedHashOptions(), func(opts BoundedHashOptions) (Strategy, error) {
+		keys, err := opts.Extractor()
+		if err != nil {
+			return nil, err
+		}
+		return NewBoundedHash(keys, opts.VirtualNodes, opts.Epsilon), nil
+	})
+}
+
+type BoundedHashOptions struct {
+	HashOptions
+	Epsilon float64 `json:"epsilon"`
+}
+
+func DefaultBoundedHashOptions() BoundedHashOptions {
+	return BoundedHashOptions{
+		HashOptions: DefaultHashOptions(),
+		Epsilon:     DefaultBoundedLoadEpsilon,
+	}
+}
+
+func (o *BoundedHashOptions) Validate() error {
+	if err := o.HashOptions.Validate(); err != nil {
+		return err
+	}
+
+	if o.Epsilon <= 0 {
+		return errors.New("epsilon must be greater than 0")
+	}
+
+	return nil
+}
+
+type BoundedHash struct {
+	keys         *HashKeyExtractor
+	virtualNodes int
+	epsilon      float64
+	rings        snapshotCache[*hashRing]
+}
+
+func NewBoundedHash(keys *HashKeyExtractor, virtualNodes int, epsilon float64) *BoundedHash {
+	if virtualNodes < 1 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	if epsilon <= 0 {
+		epsilon = DefaultBoundedLoadEpsilon
+	}
+
+	return &BoundedHash{
+		keys:         keys,
+		virtualNodes: virtualNodes,
+		epsilon:      epsilon,
+	}
+}
+
+func (b *BoundedHash) GetNextServer(pool *domain.ServerPool, r *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+	if len(servers) == 0 {
+		return nil
+	}
+
+	ring := b.rings.get(pool, func(servers []*domain.Server) *hashRing {
+		return newHashRing(servers, b.virtualNodes)
+	})
+
+	limit := b.capacity(servers)
+
+	return ring.walk(hashString(b.keys.Key(r)), func(s *domain.Server) bool {
+		return s.GetConnections() < limit
+	})
+}
+
+func (b *BoundedHash) capacity(servers []*domain.Server) int64 {
+	var total int64
+	for _, s := range servers {
+		total += s.GetConnections()
+	}
+
+	average := float64(total+1) / float64(len(servers))
+	return int64(math.Ceil(average * (1 + b.epsilon)))
+}
+
+func (b *BoundedHash) Name() string {
+	return "bounded_hash"
+}
@@ -0,0 +1,71 @@
+package balancer
+
+import (
+	"errors"
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("consistent_hash", DefaultHashOptions(), func(opts HashOptions) (Strategy, error) {
+		keys, err := opts.Extractor()
+		if err != nil {
+			return nil, err
+		}
+		return NewConsistentHash(keys, opts.VirtualNodes), nil
+	})
+}
+
+type HashOptions struct {
+	HashKeyOptions
+	VirtualNodes int `json:"virtual_nodes"`
+}
+
+func DefaultHashOptions() HashOptions {
+	return HashOptions{
+		HashKeyOptions: HashKeyOptions{HashKey: HashKeyClientIP},
+		VirtualNodes:   DefaultVirtualNodes,
+	}
+}
+
+func (o *HashOptions) Validate() error {
+	if err := o.HashKeyOptions.Validate(); err != nil {
+		return err
+	}
+
+	if o.VirtualNodes < 1 {
+		return errors.New("virtual_nodes must be at least 1")
+	}
+
+	return nil
+}
+
+type ConsistentHash struct {
+	keys         *HashKeyExtractor
+	virtualNodes int
+	rings        snapshotCache[*hashRing]
+}
+
+func NewConsistentHash(keys *HashKeyExtractor, virtualNodes int) *ConsistentHash {
+	if virtualNodes < 1 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	return &ConsistentHash{
+		keys:         keys,
+		virtualNodes: virtualNodes,
+	}
+}
+
+func (c *ConsistentHash) GetNextServer(pool *domain.ServerPool, r *http.Request) *domain.Server {
+	ring := c.rings.get(pool, func(servers []*domain.Server) *hashRing {
+		return newHashRing(servers, c.virtualNodes)
+	})
+
+	return ring.get(hashString(c.keys.Key(r)))
+}
+
+func (c *ConsistentHash) Name() string {
+	return "consistent_hash"
+}
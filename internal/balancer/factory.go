@@ -1,18 +1,121 @@
 package balancer
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"sync"
 )
 
+type OptionsValidator interface {
+	Validate() error
+}
+
+type NoOptions struct{}
+
+type registration struct {
+	decode func(raw json.RawMessage) (any, error)
+	build  func(opts any) (Strategy, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+func Register[O any](name string, defaults O, build func(opts O) (Strategy, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("balancer: strategy %s registered twice", name))
+	}
+
+	registry[name] = registration{
+		decode: func(raw json.RawMessage) (any, error) {
+			opts := defaults
+
+			raw = bytes.TrimSpace(raw)
+			if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+				decoder := json.NewDecoder(bytes.NewReader(raw))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(&opts); err != nil {
+					return nil, fmt.Errorf("invalid options for %s: %w", name, err)
+				}
+			}
+
+			if v, ok := any(&opts).(OptionsValidator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, err
+				}
+			}
+
+			return opts, nil
+		},
+		build: func(opts any) (Strategy, error) {
+			return build(opts.(O))
+		},
+	}
+}
+
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func IsRegistered(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := registry[name]
+	return ok
+}
+
+func ValidateOptions(name string, raw json.RawMessage) error {
+	reg, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = reg.decode(raw)
+	return err
+}
+
 func NewStrategy(name string) (Strategy, error) {
-	switch name {
-	case "round_robin":
-		return NewRoundRobin(), nil
-	case "weighted":
-		return NewWeighted(), nil
-	case "least_connections":
-		return NewLeastConnections(), nil
-	default:
-		return nil, fmt.Errorf("unknown balancing strategy: %s", name)
+	return NewStrategyWithOptions(name, nil)
+}
+
+func NewStrategyWithOptions(name string, raw json.RawMessage) (Strategy, error) {
+	reg, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := reg.decode(raw)
+	if err != nil {
+		return nil, err
 	}
+
+	return reg.build(opts)
+}
+
+func lookup(name string) (registration, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	reg, ok := registry[name]
+	if !ok {
+		return registration{}, fmt.Errorf("unknown balancing strategy: %s", name)
+	}
+
+	return reg, nil
 }
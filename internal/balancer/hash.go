@@ -0,0 +1,180 @@
+package balancer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"janus/internal/domain"
+)
+
+const (
+	HashKeyClientIP = "ip"
+	HashKeyHeader   = "header"
+	HashKeyCookie   = "cookie"
+	HashKeyPath     = "path"
+
+	DefaultVirtualNodes = 160
+)
+
+var validHashKeys = map[string]bool{
+	HashKeyClientIP: true,
+	HashKeyHeader:   true,
+	HashKeyCookie:   true,
+	HashKeyPath:     true,
+}
+
+type HashKeyOptions struct {
+	HashKey     string `json:"hash_key"`
+	HashKeyName string `json:"hash_key_name"`
+}
+
+func (o *HashKeyOptions) Extractor() (*HashKeyExtractor, error) {
+	return NewHashKeyExtractor(o.HashKey, o.HashKeyName)
+}
+
+func (o *HashKeyOptions) Validate() error {
+	_, err := o.Extractor()
+	return err
+}
+
+type HashKeyExtractor struct {
+	source string
+	name   string
+}
+
+func NewHashKeyExtractor(source, name string) (*HashKeyExtractor, error) {
+	if source == "" {
+		source = HashKeyClientIP
+	}
+
+	if !validHashKeys[source] {
+		return nil, fmt.Errorf("unknown hash key source: %s", source)
+	}
+
+	if (source == HashKeyHeader || source == HashKeyCookie) && name == "" {
+		return nil, fmt.Errorf("hash key source %s requires a name", source)
+	}
+
+	return &HashKeyExtractor{source: source, name: name}, nil
+}
+
+func (e *HashKeyExtractor) Key(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+
+	switch e.source {
+	case HashKeyHeader:
+		if v := r.Header.Get(e.name); v != "" {
+			return v
+		}
+	case HashKeyCookie:
+		if c, err := r.Cookie(e.name); err == nil && c.Value != "" {
+			return c.Value
+		}
+	case HashKeyPath:
+		if r.URL != nil {
+			return r.URL.Path
+		}
+	}
+
+	return clientIP(r)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// mix64 is the splitmix64 finalizer; FNV alone clusters badly for keys
+// that differ only in their last characters, like virtual node names.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+type hashRing struct {
+	hashes  []uint64
+	servers []*domain.Server
+}
+
+func newHashRing(servers []*domain.Server, virtualNodes int) *hashRing {
+	ring := &hashRing{
+		hashes:  make([]uint64, 0, len(servers)*virtualNodes),
+		servers: make([]*domain.Server, 0, len(servers)*virtualNodes),
+	}
+
+	type point struct {
+		hash   uint64
+		server *domain.Server
+	}
+
+	points := make([]point, 0, len(servers)*virtualNodes)
+	for _, s := range servers {
+		base := s.URL.String() + "#"
+		for i := 0; i < virtualNodes; i++ {
+			points = append(points, point{hashString(base + strconv.Itoa(i)), s})
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].hash < points[j].hash
+	})
+
+	for _, p := range points {
+		ring.hashes = append(ring.hashes, p.hash)
+		ring.servers = append(ring.servers, p.server)
+	}
+
+	return ring
+}
+
+func (r *hashRing) search(hash uint64) int {
+	idx := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= hash
+	})
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return idx
+}
+
+func (r *hashRing) get(hash uint64) *domain.Server {
+	if len(r.hashes) == 0 {
+		return nil
+	}
+	return r.servers[r.search(hash)]
+}
+
+func (r *hashRing) walk(hash uint64, accept func(*domain.Server) bool) *domain.Server {
+	if len(r.hashes) == 0 {
+		return nil
+	}
+
+	start := r.search(hash)
+	for i := 0; i < len(r.servers); i++ {
+		s := r.servers[(start+i)%len(r.servers)]
+		if accept(s) {
+			return s
+		}
+	}
+
+	return r.servers[start]
+}
@@ -1,16 +1,24 @@
 package balancer
 
 import (
+	"net/http"
+
 	"janus/internal/domain"
 )
 
+func init() {
+	Register("least_connections", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewLeastConnections(), nil
+	})
+}
+
 type LeastConnections struct{}
 
 func NewLeastConnections() *LeastConnections {
 	return &LeastConnections{}
 }
 
-func (l *LeastConnections) GetNextServer(pool *domain.ServerPool) *domain.Server {
+func (l *LeastConnections) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
 	servers := pool.GetHealthyServers()
 	if len(servers) == 0 {
 		return nil
@@ -0,0 +1,54 @@
+package balancer
+
+import (
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("least_latency", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewLeastLatency(), nil
+	})
+}
+
+type LeastLatency struct{}
+
+func NewLeastLatency() *LeastLatency {
+	return &LeastLatency{}
+}
+
+func (l *LeastLatency) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+
+	switch len(servers) {
+	case 0:
+		return nil
+	case 1:
+		return servers[0]
+	}
+
+	a, b := pickTwo(servers)
+	latencyA, sampledA := a.LatencyEWMA()
+	latencyB, sampledB := b.LatencyEWMA()
+
+	if !sampledA {
+		latencyA = latencyB
+	}
+	if !sampledB {
+		latencyB = latencyA
+	}
+
+	costA := float64(latencyA) * float64(a.GetConnections()+1)
+	costB := float64(latencyB) * float64(b.GetConnections()+1)
+
+	if costB < costA || (costB == costA && b.GetConnections() < a.GetConnections()) {
+		return b
+	}
+
+	return a
+}
+
+func (l *LeastLatency) Name() string {
+	return "least_latency"
+}
@@ -0,0 +1,215 @@
+package balancer
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"janus/internal/domain"
+)
+
+const DefaultOverprovisioningFactor = 1.4
+
+// localityLoadRefresh is how often tier shares are recomputed from in-flight
+// connections when a capacity is set.
+const localityLoadRefresh = 100 * time.Millisecond
+
+const (
+	localityZone = iota
+	localityRegion
+	localityRemote
+	localityTiers
+)
+
+// Locality keeps traffic in the local zone, then the local region, and only
+// then goes further. A tier's share is its healthy capacity multiplied by the
+// overprovisioning factor, reduced further when the tier holds more
+// connections than its capacity; whatever it cannot absorb overflows to the
+// next.
+type Locality struct {
+	zone     string
+	region   string
+	factor   float64
+	capacity float64
+	inner    [localityTiers]Strategy
+	rng      *randSource
+	mu       sync.Mutex
+	view     atomic.Pointer[localityView]
+}
+
+type localityView struct {
+	pool    *domain.ServerPool
+	subsets [localityTiers]*domain.ServerPool
+	loads   snapshotCache[[localityTiers]float64]
+}
+
+func NewLocality(zone, region string, factor float64, newInner func() (Strategy, error)) (*Locality, error) {
+	if zone == "" {
+		return nil, errors.New("locality zone is required")
+	}
+
+	if factor == 0 {
+		factor = DefaultOverprovisioningFactor
+	}
+	if factor < 1 {
+		return nil, errors.New("overprovisioning factor must be at least 1")
+	}
+
+	l := &Locality{
+		zone:   zone,
+		region: region,
+		factor: factor,
+		rng:    newRandSource(),
+	}
+
+	for i := range l.inner {
+		inner, err := newInner()
+		if err != nil {
+			return nil, err
+		}
+		l.inner[i] = inner
+	}
+
+	return l, nil
+}
+
+// SetCapacity sets how many in-flight connections a server can hold per unit
+// of weight. A tier holding more than that sheds the excess to the next tier.
+// Zero, the default, routes by health alone. Call it before serving traffic.
+func (l *Locality) SetCapacity(connectionsPerWeight float64) {
+	l.capacity = connectionsPerWeight
+}
+
+func (l *Locality) GetNextServer(pool *domain.ServerPool, r *http.Request) *domain.Server {
+	view := l.viewFor(pool)
+	loads := view.loads.get(pool, func([]*domain.Server) [localityTiers]float64 {
+		return l.tierLoads(view.subsets)
+	})
+
+	draw := l.rng.Float64()
+	for i, load := range loads {
+		if draw < load {
+			if server := l.inner[i].GetNextServer(view.subsets[i], r); server != nil {
+				return server
+			}
+			break
+		}
+		draw -= load
+	}
+
+	for i, load := range loads {
+		if load > 0 {
+			if server := l.inner[i].GetNextServer(view.subsets[i], r); server != nil {
+				return server
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *Locality) Done(server *domain.Server, r *http.Request, result Result) {
+	if observer, ok := l.inner[l.tier(server)].(Observer); ok {
+		observer.Done(server, r, result)
+	}
+}
+
+func (l *Locality) Name() string {
+	return "locality/" + l.inner[localityZone].Name()
+}
+
+func (l *Locality) viewFor(pool *domain.ServerPool) *localityView {
+	if view := l.view.Load(); view != nil && view.pool == pool {
+		return view
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if view := l.view.Load(); view != nil && view.pool == pool {
+		return view
+	}
+
+	view := &localityView{pool: pool}
+	if l.capacity > 0 {
+		view.loads.maxAge = localityLoadRefresh
+	}
+	for i := range view.subsets {
+		tier := i
+		view.subsets[i] = pool.Subset(func(s *domain.Server) bool {
+			return l.tier(s) == tier
+		})
+	}
+	l.view.Store(view)
+
+	return view
+}
+
+func (l *Locality) tier(s *domain.Server) int {
+	switch {
+	case s.Zone == l.zone && (l.region == "" || s.Region == l.region):
+		return localityZone
+	case l.region != "" && s.Region == l.region:
+		return localityRegion
+	default:
+		return localityRemote
+	}
+}
+
+func (l *Locality) tierLoads(subsets [localityTiers]*domain.ServerPool) [localityTiers]float64 {
+	var loads [localityTiers]float64
+
+	remaining := 1.0
+	for i, subset := range subsets {
+		loads[i] = min(remaining, l.availability(subset))
+		remaining -= loads[i]
+	}
+
+	// When every tier is degraded the shares do not add up to one; scale
+	// them so traffic is still spread in proportion to what is left.
+	if assigned := 1 - remaining; assigned > 0 && remaining > 0 {
+		for i := range loads {
+			loads[i] /= assigned
+		}
+	}
+
+	return loads
+}
+
+func (l *Locality) availability(subset *domain.ServerPool) float64 {
+	routable := subset.GetHealthyServers()
+	if len(routable) == 0 {
+		return 0
+	}
+
+	total, alive := 0, 0
+	var connections int64
+	for _, s := range subset.GetServers() {
+		total += s.GetWeight()
+		if s.IsAvailable() {
+			alive += s.GetWeight()
+			connections += s.GetConnections()
+		}
+	}
+
+	// Routable but dead servers mean the pool is in panic mode; weigh the
+	// tier by what it is still being sent traffic for.
+	if alive == 0 {
+		for _, s := range routable {
+			alive += s.GetWeight()
+			connections += s.GetConnections()
+		}
+	}
+
+	share := min(1, l.factor*float64(alive)/float64(total))
+
+	if l.capacity > 0 {
+		if capacity := l.capacity * float64(alive); float64(connections) > capacity {
+			share *= capacity / float64(connections)
+		}
+	}
+
+	return share
+}
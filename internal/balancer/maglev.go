@@ -0,0 +1,190 @@
+package balancer
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"slices"
+
+	"janus/internal/domain"
+)
+
+const DefaultMaglevTableSize = 65537
+
+// slowStartSteps is how many discrete levels a warming server's weight
+// moves through. Coarse levels keep the weights' common divisor intact, so
+// schedules stay about as small as in steady state, and snapshots are only
+// rebuilt when a server moves to the next level.
+const slowStartSteps = 10
+
+func init() {
+	Register("maglev", DefaultMaglevOptions(), func(opts MaglevOptions) (Strategy, error) {
+		keys, err := opts.Extractor()
+		if err != nil {
+			return nil, err
+		}
+		return NewMaglev(keys, opts.TableSize)
+	})
+}
+
+type MaglevOptions struct {
+	HashKeyOptions
+	TableSize int `json:"table_size"`
+}
+
+func DefaultMaglevOptions() MaglevOptions {
+	return MaglevOptions{
+		HashKeyOptions: HashKeyOptions{HashKey: HashKeyClientIP},
+		TableSize:      DefaultMaglevTableSize,
+	}
+}
+
+func (o *MaglevOptions) Validate() error {
+	if err := o.HashKeyOptions.Validate(); err != nil {
+		return err
+	}
+
+	if !isPrime(o.TableSize) {
+		return fmt.Errorf("table_size must be a prime number, got %d", o.TableSize)
+	}
+
+	return nil
+}
+
+type maglevTable struct {
+	entries []*domain.Server
+}
+
+type Maglev struct {
+	keys      *HashKeyExtractor
+	tableSize int
+	tables    snapshotCache[*maglevTable]
+}
+
+func NewMaglev(keys *HashKeyExtractor, tableSize int) (*Maglev, error) {
+	if tableSize == 0 {
+		tableSize = DefaultMaglevTableSize
+	}
+
+	if !isPrime(tableSize) {
+		return nil, fmt.Errorf("maglev table size must be a prime number, got %d", tableSize)
+	}
+
+	return &Maglev{
+		keys:      keys,
+		tableSize: tableSize,
+	}, nil
+}
+
+func (m *Maglev) GetNextServer(pool *domain.ServerPool, r *http.Request) *domain.Server {
+	table := m.tables.getWeighted(pool, func(servers []*domain.Server) *maglevTable {
+		return newMaglevTable(servers, m.tableSize)
+	})
+
+	if len(table.entries) == 0 {
+		return nil
+	}
+
+	return table.entries[hashString(m.keys.Key(r))%uint64(len(table.entries))]
+}
+
+func (m *Maglev) Name() string {
+	return "maglev"
+}
+
+func newMaglevTable(servers []*domain.Server, size int) *maglevTable {
+	if len(servers) == 0 {
+		return &maglevTable{}
+	}
+
+	m := uint64(size)
+	offsets := make([]uint64, len(servers))
+	skips := make([]uint64, len(servers))
+	next := make([]uint64, len(servers))
+	turns := normalizedWeights(servers)
+
+	for i, s := range servers {
+		h := hashString(s.URL.String())
+		offsets[i] = h % m
+		skips[i] = mix64(h^0x9e3779b97f4a7c15)%(m-1) + 1
+	}
+
+	// Every round each server earns credit in proportion to its weight and
+	// places at most one entry once it has a full turn, so the heaviest
+	// server places one entry per round and no server can fill the table
+	// before the others had their share.
+	heaviest := slices.Max(turns)
+	credit := make([]int, len(servers))
+
+	entries := make([]*domain.Server, size)
+	filled := 0
+
+	for {
+		for i, s := range servers {
+			credit[i] += turns[i]
+			if credit[i] < heaviest {
+				continue
+			}
+			credit[i] -= heaviest
+
+			c := (offsets[i] + next[i]*skips[i]) % m
+			for entries[c] != nil {
+				next[i]++
+				c = (offsets[i] + next[i]*skips[i]) % m
+			}
+
+			entries[c] = s
+			next[i]++
+			filled++
+
+			if filled == size {
+				return &maglevTable{entries: entries}
+			}
+		}
+	}
+}
+
+func normalizedWeights(servers []*domain.Server) []int {
+	weights := scaledWeights(servers)
+	divisor := 0
+	for _, w := range weights {
+		divisor = gcd(divisor, w)
+	}
+	if divisor < 1 {
+		divisor = 1
+	}
+
+	for i := range weights {
+		weights[i] = max(weights[i]/divisor, 1)
+	}
+	return weights
+}
+
+// scaledWeight is the effective weight in units of a slow-start step;
+// steady-state weights scale uniformly and the factor cancels out in
+// normalizedWeights.
+func scaledWeight(s *domain.Server) int {
+	weight := s.GetWeight()
+	step := math.Round(s.EffectiveWeight() / float64(weight) * slowStartSteps)
+	return weight * max(int(step), 1)
+}
+
+func scaledWeights(servers []*domain.Server) []int {
+	weights := make([]int, len(servers))
+	for i, s := range servers {
+		weights[i] = scaledWeight(s)
+	}
+	return weights
+}
+
+func isPrime(n int) bool {
+	return n >= 2 && big.NewInt(int64(n)).ProbablyPrime(0)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
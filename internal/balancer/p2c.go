@@ -0,0 +1,51 @@
+package balancer
+
+import (
+	"math/rand/v2"
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("p2c", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewP2C(), nil
+	})
+}
+
+type P2C struct{}
+
+func NewP2C() *P2C {
+	return &P2C{}
+}
+
+func (p *P2C) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+
+	switch len(servers) {
+	case 0:
+		return nil
+	case 1:
+		return servers[0]
+	}
+
+	a, b := pickTwo(servers)
+	if b.GetConnections() < a.GetConnections() {
+		return b
+	}
+
+	return a
+}
+
+func (p *P2C) Name() string {
+	return "p2c"
+}
+
+func pickTwo(servers []*domain.Server) (*domain.Server, *domain.Server) {
+	i := rand.IntN(len(servers))
+	j := rand.IntN(len(servers) - 1)
+	if j >= i {
+		j++
+	}
+	return servers[i], servers[j]
+}
@@ -0,0 +1,63 @@
+package balancer
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// randSource hands out generators for the random strategies. Unseeded
+// sources keep per-P generators in a sync.Pool, so concurrent callers never
+// contend on a shared generator or mutex. The pool may drop generators at
+// any time, so seeded sources use a single generator behind a mutex to stay
+// reproducible.
+type randSource struct {
+	mu     sync.Mutex
+	seeded *rand.Rand
+	pool   sync.Pool
+}
+
+func newRandSource() *randSource {
+	s := &randSource{}
+	s.pool.New = func() any {
+		return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return s
+}
+
+func newSeededRandSource(seed uint64) *randSource {
+	return &randSource{seeded: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (s *randSource) acquire() *rand.Rand {
+	if s.seeded != nil {
+		s.mu.Lock()
+		return s.seeded
+	}
+	return s.pool.Get().(*rand.Rand)
+}
+
+func (s *randSource) release(r *rand.Rand) {
+	if s.seeded != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.pool.Put(r)
+}
+
+func (s *randSource) IntN(n int) int {
+	r := s.acquire()
+	defer s.release(r)
+	return r.IntN(n)
+}
+
+func (s *randSource) Int64N(n int64) int64 {
+	r := s.acquire()
+	defer s.release(r)
+	return r.Int64N(n)
+}
+
+func (s *randSource) Float64() float64 {
+	r := s.acquire()
+	defer s.release(r)
+	return r.Float64()
+}
@@ -0,0 +1,38 @@
+package balancer
+
+import (
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("random", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewRandom(), nil
+	})
+}
+
+type Random struct {
+	rng *randSource
+}
+
+func NewRandom() *Random {
+	return &Random{rng: newRandSource()}
+}
+
+func NewRandomWithSeed(seed uint64) *Random {
+	return &Random{rng: newSeededRandSource(seed)}
+}
+
+func (r *Random) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+	if len(servers) == 0 {
+		return nil
+	}
+
+	return servers[r.rng.IntN(len(servers))]
+}
+
+func (r *Random) Name() string {
+	return "random"
+}
@@ -2,9 +2,16 @@ package balancer
 
 import (
 	"janus/internal/domain"
+	"net/http"
 	"sync/atomic"
 )
 
+func init() {
+	Register("round_robin", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewRoundRobin(), nil
+	})
+}
+
 type RoundRobin struct {
 	current atomic.Uint64
 }
@@ -13,7 +20,7 @@ func NewRoundRobin() *RoundRobin {
 	return &RoundRobin{}
 }
 
-func (r *RoundRobin) GetNextServer(pool *domain.ServerPool) *domain.Server {
+func (r *RoundRobin) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
 	servers := pool.GetHealthyServers()
 	if len(servers) == 0 {
 		return nil
@@ -0,0 +1,143 @@
+package balancer
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"janus/internal/domain"
+)
+
+// slowStartRefresh is how often a weight-aware snapshot is checked for new
+// slow-start levels while one of its servers is still ramping up.
+const slowStartRefresh = 500 * time.Millisecond
+
+type snapshot[T any] struct {
+	pool       *domain.ServerPool
+	generation uint64
+	expires    time.Time
+	weights    []int
+	value      T
+}
+
+func (s *snapshot[T]) matches(pool *domain.ServerPool, generation uint64) bool {
+	return s != nil && s.pool == pool && s.generation == generation
+}
+
+func (s *snapshot[T]) expired() bool {
+	return !s.expires.IsZero() && !time.Now().Before(s.expires)
+}
+
+type snapshotCache[T any] struct {
+	// maxAge, when set, makes snapshots expire and get rebuilt in the
+	// background even if the pool did not change.
+	maxAge     time.Duration
+	mu         sync.Mutex
+	current    atomic.Pointer[snapshot[T]]
+	refreshing atomic.Bool
+}
+
+func (c *snapshotCache[T]) get(pool *domain.ServerPool, build func(servers []*domain.Server) T) T {
+	value, _ := c.load(pool, build, false, false)
+	return value
+}
+
+// getWeighted is get for snapshots built from effective weights. While a
+// server is in slow start the snapshot is refreshed in the background, and
+// callers keep using the current one until the new one is ready.
+func (c *snapshotCache[T]) getWeighted(pool *domain.ServerPool, build func(servers []*domain.Server) T) T {
+	value, _ := c.load(pool, build, true, false)
+	return value
+}
+
+// getWeightedLatest is getWeighted for snapshots that are too expensive to
+// build on the request path. When the pool changes it keeps returning the
+// previous value, reported as not current, until the rebuild started in the
+// background is done. Only the first snapshot of a pool is built in place.
+func (c *snapshotCache[T]) getWeightedLatest(pool *domain.ServerPool, build func(servers []*domain.Server) T) (T, bool) {
+	return c.load(pool, build, true, true)
+}
+
+func (c *snapshotCache[T]) load(pool *domain.ServerPool, build func(servers []*domain.Server) T, weighted, background bool) (T, bool) {
+	generation := pool.Generation()
+	s := c.current.Load()
+
+	switch {
+	case s.matches(pool, generation):
+		if s.expired() && c.refreshing.CompareAndSwap(false, true) {
+			go c.refresh(pool, build, weighted, s)
+		}
+		return s.value, true
+	case background && s != nil && s.pool == pool:
+		if c.refreshing.CompareAndSwap(false, true) {
+			go c.refresh(pool, build, weighted, s)
+		}
+		return s.value, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s := c.current.Load(); s.matches(pool, generation) {
+		return s.value, true
+	}
+
+	s = c.build(pool, build, weighted, nil)
+	c.current.Store(s)
+
+	return s.value, true
+}
+
+func (c *snapshotCache[T]) refresh(pool *domain.ServerPool, build func(servers []*domain.Server) T, weighted bool, previous *snapshot[T]) {
+	defer c.refreshing.Store(false)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current.Load() != previous {
+		return
+	}
+	c.current.Store(c.build(pool, build, weighted, previous))
+}
+
+// build creates a snapshot of the healthy servers. A weighted snapshot whose
+// weights did not change since the previous one keeps its value.
+func (c *snapshotCache[T]) build(pool *domain.ServerPool, build func(servers []*domain.Server) T, weighted bool, previous *snapshot[T]) *snapshot[T] {
+	generation := pool.Generation()
+	servers := pool.GetHealthyServers()
+	s := &snapshot[T]{pool: pool, generation: generation}
+	if c.maxAge > 0 {
+		s.expires = time.Now().Add(c.maxAge)
+	}
+
+	if weighted {
+		s.weights = scaledWeights(servers)
+		if anyWarming(servers) {
+			s.expires = time.Now().Add(slowStartRefresh)
+		}
+	}
+
+	if weighted && previous.matches(pool, generation) && slices.Equal(previous.weights, s.weights) {
+		s.value = previous.value
+	} else {
+		s.value = build(servers)
+	}
+
+	return s
+}
+
+func (c *snapshotCache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current.Store(nil)
+}
+
+func anyWarming(servers []*domain.Server) bool {
+	for _, s := range servers {
+		if s.IsWarming() {
+			return true
+		}
+	}
+	return false
+}
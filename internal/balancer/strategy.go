@@ -1,10 +1,27 @@
 package balancer
 
 import (
+	"net/http"
+	"time"
+
 	"janus/internal/domain"
 )
 
 type Strategy interface {
-	GetNextServer(pool *domain.ServerPool) *domain.Server
+	GetNextServer(pool *domain.ServerPool, r *http.Request) *domain.Server
 	Name() string
 }
+
+type Result struct {
+	StatusCode int
+	Err        error
+	Latency    time.Duration
+}
+
+func (r Result) Failed() bool {
+	return r.Err != nil || r.StatusCode >= http.StatusInternalServerError
+}
+
+type Observer interface {
+	Done(server *domain.Server, r *http.Request, result Result)
+}
@@ -2,81 +2,197 @@ package balancer
 
 import (
 	"janus/internal/domain"
-	"sync"
+	"math"
+	"net/http"
+	"sync/atomic"
 )
 
+func init() {
+	Register("weighted", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewWeighted(), nil
+	})
+}
+
+type weightedSchedule struct {
+	order    []*domain.Server
+	position atomic.Uint64
+}
+
+func (s *weightedSchedule) next() *domain.Server {
+	next := s.position.Add(1)
+	return s.order[(next-1)%uint64(len(s.order))]
+}
+
+func (s *weightedSchedule) nextAvailable() *domain.Server {
+	for range s.order {
+		if server := s.next(); server.IsAvailable() {
+			return server
+		}
+	}
+	return nil
+}
+
 type Weighted struct {
-	mu             sync.Mutex
-	currentWeights map[*domain.Server]int
-	initialized    bool
+	schedules snapshotCache[*weightedSchedule]
 }
 
 func NewWeighted() *Weighted {
-	return &Weighted{
-		currentWeights: make(map[*domain.Server]int),
-	}
+	return &Weighted{}
 }
 
-func (w *Weighted) GetNextServer(pool *domain.ServerPool) *domain.Server {
-	w.mu.Lock()
-	defer w.mu.Unlock()
+func (w *Weighted) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	schedule, current := w.schedules.getWeightedLatest(pool, newWeightedSchedule)
 
-	servers := pool.GetHealthyServers()
-	if len(servers) == 0 {
+	// While the schedule for a changed pool is being built, the previous one
+	// is used for the servers that still take traffic.
+	if !current {
+		if server := schedule.nextAvailable(); server != nil {
+			return server
+		}
+		schedule = w.schedules.getWeighted(pool, newWeightedSchedule)
+	}
+
+	if len(schedule.order) == 0 {
 		return nil
 	}
+	return schedule.next()
+}
 
-	if len(w.currentWeights) > len(servers) {
-		healthyMap := make(map[*domain.Server]bool, len(servers))
-		for _, s := range servers {
-			healthyMap[s] = true
-		}
+func (w *Weighted) Name() string {
+	return "weighted"
+}
 
-		for s := range w.currentWeights {
-			if !healthyMap[s] {
-				delete(w.currentWeights, s)
-			}
-		}
-	}
+func (w *Weighted) Reset() {
+	w.schedules.reset()
+}
+
+// newWeightedSchedule unrolls one full cycle of smooth weighted round-robin.
+// After sum(weights) picks every current weight is back to zero, so the
+// cycle repeats exactly and can be replayed by index.
+//
+// At step t a server's current weight is t*weight - total*picks, a line in t
+// that drops by total each time the server is picked. A kinetic tournament
+// over those lines finds the highest one without visiting every server on
+// every step, which keeps the build at O(total log n).
+func newWeightedSchedule(servers []*domain.Server) *weightedSchedule {
+	weights := normalizedWeights(servers)
 
 	totalWeight := 0
-	for _, s := range servers {
-		totalWeight += s.Weight
+	for _, weight := range weights {
+		totalWeight += weight
 	}
 
-	for _, s := range servers {
-		if _, exists := w.currentWeights[s]; !exists {
-			w.currentWeights[s] = 0
-		}
-		w.currentWeights[s] += s.Weight
+	lines := newLineTournament(weights)
+	order := make([]*domain.Server, 0, totalWeight)
+
+	for step := 1; step <= totalWeight; step++ {
+		selected := lines.highest(step)
+		order = append(order, servers[selected])
+		lines.lower(selected, totalWeight, step)
 	}
 
-	var selected *domain.Server
-	maxWeight := 0
+	return &weightedSchedule{order: order}
+}
+
+// lineTournament is a tournament tree over lines slope*t+offset. Every node
+// keeps the winner of its subtree and the step at which that result has to
+// be recomputed because a losing line overtakes it. Ties go to the lower
+// index, as in the plain smooth weighted round-robin loop.
+type lineTournament struct {
+	slopes  []int
+	offsets []int
+	leaves  int
+	winners []int
+	expires []int
+}
 
-	for _, s := range servers {
-		if w.currentWeights[s] > maxWeight {
-			maxWeight = w.currentWeights[s]
-			selected = s
+func newLineTournament(slopes []int) *lineTournament {
+	leaves := 1
+	for leaves < len(slopes) {
+		leaves *= 2
+	}
+
+	l := &lineTournament{
+		slopes:  slopes,
+		offsets: make([]int, len(slopes)),
+		leaves:  leaves,
+		winners: make([]int, 2*leaves),
+		expires: make([]int, 2*leaves),
+	}
+
+	for i := range leaves {
+		l.winners[leaves+i] = -1
+		if i < len(slopes) {
+			l.winners[leaves+i] = i
 		}
+		l.expires[leaves+i] = math.MaxInt
+	}
+	for node := leaves - 1; node > 0; node-- {
+		l.combine(node, 1)
 	}
 
-	if selected == nil {
-		return servers[0]
+	return l
+}
+
+func (l *lineTournament) highest(step int) int {
+	l.refresh(1, step)
+	return l.winners[1]
+}
+
+// lower moves line i down by amount. It must follow highest for the same step.
+func (l *lineTournament) lower(i, amount, step int) {
+	l.offsets[i] -= amount
+	for node := (l.leaves + i) / 2; node > 0; node /= 2 {
+		l.combine(node, step)
 	}
+}
+
+func (l *lineTournament) refresh(node, step int) {
+	if l.expires[node] > step {
+		return
+	}
+	l.refresh(2*node, step)
+	l.refresh(2*node+1, step)
+	l.combine(node, step)
+}
 
-	w.currentWeights[selected] -= totalWeight
+func (l *lineTournament) combine(node, step int) {
+	left, right := l.winners[2*node], l.winners[2*node+1]
+	expires := min(l.expires[2*node], l.expires[2*node+1])
+
+	switch {
+	case right < 0:
+		l.winners[node] = left
+	case left < 0:
+		l.winners[node] = right
+	default:
+		winner, loser := left, right
+		if l.beats(right, left, step) {
+			winner, loser = right, left
+		}
+		l.winners[node] = winner
+		expires = min(expires, l.overtakes(winner, loser))
+	}
 
-	return selected
+	l.expires[node] = expires
 }
 
-func (w *Weighted) Name() string {
-	return "weighted"
+func (l *lineTournament) beats(a, b, step int) bool {
+	va := l.slopes[a]*step + l.offsets[a]
+	vb := l.slopes[b]*step + l.offsets[b]
+	return va > vb || (va == vb && a < b)
 }
 
-func (w *Weighted) Reset() {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	w.currentWeights = make(map[*domain.Server]int)
-	w.initialized = false
+// overtakes returns the first step at which loser beats winner.
+func (l *lineTournament) overtakes(winner, loser int) int {
+	rate := l.slopes[loser] - l.slopes[winner]
+	if rate <= 0 {
+		return math.MaxInt
+	}
+
+	gap := l.offsets[winner] - l.offsets[loser]
+	if gap%rate == 0 && loser < winner {
+		return gap / rate
+	}
+	return gap/rate + 1
 }
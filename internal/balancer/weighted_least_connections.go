@@ -0,0 +1,60 @@
+package balancer
+
+import (
+	"math/rand/v2"
+	"net/http"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("weighted_least_connections", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewWeightedLeastConnections(), nil
+	})
+}
+
+type WeightedLeastConnections struct{}
+
+func NewWeightedLeastConnections() *WeightedLeastConnections {
+	return &WeightedLeastConnections{}
+}
+
+func (w *WeightedLeastConnections) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+	if len(servers) == 0 {
+		return nil
+	}
+
+	var selected *domain.Server
+	var bestConnections, bestWeight float64
+	ties := 0
+
+	for _, s := range servers {
+		connections := float64(s.GetConnections())
+		weight := s.EffectiveWeight()
+
+		if selected == nil {
+			selected, bestConnections, bestWeight, ties = s, connections, weight, 1
+			continue
+		}
+
+		lhs := connections * bestWeight
+		rhs := bestConnections * weight
+
+		switch {
+		case lhs < rhs:
+			selected, bestConnections, bestWeight, ties = s, connections, weight, 1
+		case lhs == rhs:
+			ties++
+			if rand.IntN(ties) == 0 {
+				selected, bestConnections, bestWeight = s, connections, weight
+			}
+		}
+	}
+
+	return selected
+}
+
+func (w *WeightedLeastConnections) Name() string {
+	return "weighted_least_connections"
+}
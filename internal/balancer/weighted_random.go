@@ -0,0 +1,65 @@
+package balancer
+
+import (
+	"net/http"
+	"sort"
+
+	"janus/internal/domain"
+)
+
+func init() {
+	Register("weighted_random", NoOptions{}, func(NoOptions) (Strategy, error) {
+		return NewWeightedRandom(), nil
+	})
+}
+
+type cumulativeWeights struct {
+	servers []*domain.Server
+	totals  []int64
+}
+
+type WeightedRandom struct {
+	rng     *randSource
+	weights snapshotCache[*cumulativeWeights]
+}
+
+func NewWeightedRandom() *WeightedRandom {
+	return &WeightedRandom{rng: newRandSource()}
+}
+
+func NewWeightedRandomWithSeed(seed uint64) *WeightedRandom {
+	return &WeightedRandom{rng: newSeededRandSource(seed)}
+}
+
+func (w *WeightedRandom) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	cw := w.weights.getWeighted(pool, newCumulativeWeights)
+	if len(cw.servers) == 0 {
+		return nil
+	}
+
+	x := w.rng.Int64N(cw.totals[len(cw.totals)-1])
+	idx := sort.Search(len(cw.totals), func(i int) bool {
+		return cw.totals[i] > x
+	})
+
+	return cw.servers[idx]
+}
+
+func (w *WeightedRandom) Name() string {
+	return "weighted_random"
+}
+
+func newCumulativeWeights(servers []*domain.Server) *cumulativeWeights {
+	cw := &cumulativeWeights{
+		servers: servers,
+		totals:  make([]int64, len(servers)),
+	}
+
+	var total int64
+	for i, s := range servers {
+		total += int64(scaledWeight(s))
+		cw.totals[i] = total
+	}
+
+	return cw
+}
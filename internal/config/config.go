@@ -4,31 +4,131 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+	"janus/internal/server"
 )
 
 const (
 	DefaultPort            = 8080
 	DefaultHealthCheckTime = 5
 	DefaultStrategy        = "round_robin"
+	DefaultLatencyDecay    = 10
+	DefaultStickyCookie    = "janus_server"
+	DefaultStickyTTL       = 3600
+	MinStickyKeyLength     = 16
+	DefaultHealthThreshold = 1
+
+	DefaultBreakerConsecutiveFailures = 5
+	DefaultBreakerErrorRate           = 0.5
+	DefaultBreakerMinRequests         = 20
+	DefaultBreakerWindow              = 10
+	DefaultBreakerOpenTimeout         = 5
+	DefaultBreakerHalfOpenRequests    = 1
 )
 
-var ValidStrategies = map[string]bool{
-	"round_robin":       true,
-	"weighted":          true,
-	"least_connections": true,
-}
+const (
+	HealthCheckTCP  = "tcp"
+	HealthCheckHTTP = "http"
+	HealthCheckGRPC = "grpc"
+	HealthCheckTLS  = "tls"
+	HealthCheckExec = "exec"
+)
 
 type Config struct {
-	Port            int            `json:"port"`
-	HealthCheckTime int            `json:"health_check_time"`
-	Strategy        string         `json:"strategy"`
-	Servers         []ServerConfig `json:"backends"`
+	Port                   int               `json:"port"`
+	AdminPort              int               `json:"admin_port"`
+	HealthCheckTime        int               `json:"health_check_time"`
+	HealthCheckJitter      *float64          `json:"health_check_jitter"`
+	HealthCheckConcurrency int               `json:"health_check_concurrency"`
+	HealthCheck            HealthCheck       `json:"health_check"`
+	HealthyThreshold       int               `json:"healthy_threshold"`
+	UnhealthyThreshold     int               `json:"unhealthy_threshold"`
+	LatencyDecayTime       int               `json:"latency_decay_time"`
+	Strategy               string            `json:"strategy"`
+	StrategyOptions        json.RawMessage   `json:"strategy_options"`
+	StickySession          *StickySession    `json:"sticky_session"`
+	FailoverThreshold      float64           `json:"failover_threshold"`
+	PanicThreshold         float64           `json:"panic_threshold"`
+	Locality               *Locality         `json:"locality"`
+	SlowStart              *SlowStart        `json:"slow_start"`
+	OutlierDetection       *OutlierDetection `json:"outlier_detection"`
+	CircuitBreaker         *CircuitBreaker   `json:"circuit_breaker"`
+	Servers                []ServerConfig    `json:"backends"`
+}
+
+type StickySession struct {
+	CookieName string `json:"cookie_name"`
+	TTL        int    `json:"ttl"`
+	SigningKey string `json:"signing_key"`
+}
+
+type Locality struct {
+	Zone                   string  `json:"zone"`
+	Region                 string  `json:"region"`
+	OverprovisioningFactor float64 `json:"overprovisioning_factor"`
+	ConnectionsPerWeight   float64 `json:"connections_per_weight"`
+}
+
+type SlowStart struct {
+	Window           int     `json:"window"`
+	Aggression       float64 `json:"aggression"`
+	MinWeightPercent float64 `json:"min_weight_percent"`
+}
+
+type OutlierDetection struct {
+	Consecutive5xx           int     `json:"consecutive_5xx"`
+	ConsecutiveGatewayErrors int     `json:"consecutive_gateway_errors"`
+	BaseEjectionTime         int     `json:"base_ejection_time"`
+	MaxEjectionTime          int     `json:"max_ejection_time"`
+	MaxEjectionPercent       float64 `json:"max_ejection_percent"`
+	UnejectOnHealthCheck     bool    `json:"uneject_on_health_check"`
+}
+
+type CircuitBreaker struct {
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	ErrorRate           float64 `json:"error_rate"`
+	MinRequests         int     `json:"min_requests"`
+	Window              int     `json:"window"`
+	OpenTimeout         int     `json:"open_timeout"`
+	HalfOpenRequests    int     `json:"half_open_requests"`
+}
+
+type HealthCheck struct {
+	Type              string            `json:"type"`
+	Path              string            `json:"path"`
+	Method            string            `json:"method"`
+	Headers           map[string]string `json:"headers"`
+	Host              string            `json:"host"`
+	ExpectedStatus    []string          `json:"expected_status"`
+	Body              string            `json:"body"`
+	BodyRegex         string            `json:"body_regex"`
+	Service           string            `json:"service"`
+	CAFile            string            `json:"ca_file"`
+	ServerName        string            `json:"server_name"`
+	CertExpiryWarning int               `json:"cert_expiry_warning"`
+	CertExpiryFailure int               `json:"cert_expiry_failure"`
+	Command           []string          `json:"command"`
+	Address           string            `json:"address"`
+	Port              int               `json:"port"`
+	Timeout           int               `json:"timeout"`
 }
 
 type ServerConfig struct {
-	URL    string `json:"url"`
-	Weight int    `json:"weight"`
+	URL         string       `json:"url"`
+	Weight      int          `json:"weight"`
+	Priority    int          `json:"priority"`
+	Zone        string       `json:"zone"`
+	Region      string       `json:"region"`
+	HealthCheck *HealthCheck `json:"health_check"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -62,14 +162,93 @@ func (c *Config) applyDefaults() {
 	if c.HealthCheckTime == 0 {
 		c.HealthCheckTime = DefaultHealthCheckTime
 	}
+	if c.HealthCheckJitter == nil {
+		jitter := server.DefaultHealthCheckJitter
+		c.HealthCheckJitter = &jitter
+	}
+	if c.HealthCheckConcurrency == 0 {
+		c.HealthCheckConcurrency = server.DefaultHealthCheckConcurrency
+	}
+	if c.HealthyThreshold == 0 {
+		c.HealthyThreshold = DefaultHealthThreshold
+	}
+	if c.UnhealthyThreshold == 0 {
+		c.UnhealthyThreshold = DefaultHealthThreshold
+	}
+	if c.LatencyDecayTime == 0 {
+		c.LatencyDecayTime = DefaultLatencyDecay
+	}
 	if c.Strategy == "" {
 		c.Strategy = DefaultStrategy
 	}
 
+	if c.StickySession != nil {
+		if c.StickySession.CookieName == "" {
+			c.StickySession.CookieName = DefaultStickyCookie
+		}
+		if c.StickySession.TTL == 0 {
+			c.StickySession.TTL = DefaultStickyTTL
+		}
+	}
+
+	if c.Locality != nil && c.Locality.OverprovisioningFactor == 0 {
+		c.Locality.OverprovisioningFactor = balancer.DefaultOverprovisioningFactor
+	}
+
+	if c.SlowStart != nil {
+		if c.SlowStart.Aggression == 0 {
+			c.SlowStart.Aggression = domain.DefaultSlowStartAggression
+		}
+		if c.SlowStart.MinWeightPercent == 0 {
+			c.SlowStart.MinWeightPercent = domain.DefaultSlowStartMinPercent
+		}
+	}
+
+	if o := c.OutlierDetection; o != nil {
+		if o.Consecutive5xx == 0 {
+			o.Consecutive5xx = server.DefaultConsecutive5xx
+		}
+		if o.BaseEjectionTime == 0 {
+			o.BaseEjectionTime = int(server.DefaultBaseEjectionTime / time.Second)
+		}
+		if o.MaxEjectionTime == 0 {
+			o.MaxEjectionTime = max(int(server.DefaultMaxEjectionTime/time.Second), o.BaseEjectionTime)
+		}
+		if o.MaxEjectionPercent == 0 {
+			o.MaxEjectionPercent = server.DefaultMaxEjectionPercent
+		}
+	}
+
+	if b := c.CircuitBreaker; b != nil {
+		if b.ConsecutiveFailures == 0 {
+			b.ConsecutiveFailures = DefaultBreakerConsecutiveFailures
+		}
+		if b.ErrorRate == 0 {
+			b.ErrorRate = DefaultBreakerErrorRate
+		}
+		if b.MinRequests == 0 {
+			b.MinRequests = DefaultBreakerMinRequests
+		}
+		if b.Window == 0 {
+			b.Window = DefaultBreakerWindow
+		}
+		if b.OpenTimeout == 0 {
+			b.OpenTimeout = DefaultBreakerOpenTimeout
+		}
+		if b.HalfOpenRequests == 0 {
+			b.HalfOpenRequests = DefaultBreakerHalfOpenRequests
+		}
+	}
+
+	c.HealthCheck.applyDefaults()
+
 	for i := range c.Servers {
 		if c.Servers[i].Weight == 0 {
 			c.Servers[i].Weight = 1
 		}
+
+		check := c.HealthCheck.merge(c.Servers[i].HealthCheck)
+		c.Servers[i].HealthCheck = &check
 	}
 }
 
@@ -78,18 +257,96 @@ func (c *Config) Validate() error {
 		return errors.New("port must be between 1 and 65535")
 	}
 
+	if c.AdminPort < 0 || c.AdminPort > 65535 {
+		return errors.New("admin_port must be between 1 and 65535")
+	}
+
+	if c.AdminPort == c.Port {
+		return errors.New("admin_port must differ from port")
+	}
+
 	if c.HealthCheckTime < 1 {
 		return errors.New("health_check_time must be at least 1 second")
 	}
 
-	if !ValidStrategies[c.Strategy] {
-		return fmt.Errorf("unknown strategy: %s (valid: round_robin, weighted, least_connections)", c.Strategy)
+	if j := c.HealthCheckJitter; j != nil && (*j < 0 || *j > 1) {
+		return errors.New("health_check_jitter must be between 0 and 1")
+	}
+
+	if c.HealthCheckConcurrency < 1 {
+		return errors.New("health_check_concurrency must be at least 1")
+	}
+
+	if c.HealthyThreshold < 1 {
+		return errors.New("healthy_threshold must be at least 1")
+	}
+
+	if c.UnhealthyThreshold < 1 {
+		return errors.New("unhealthy_threshold must be at least 1")
+	}
+
+	if c.LatencyDecayTime < 1 {
+		return errors.New("latency_decay_time must be at least 1 second")
+	}
+
+	if !balancer.IsRegistered(c.Strategy) {
+		return fmt.Errorf("unknown strategy: %s (valid: %s)", c.Strategy, strings.Join(balancer.Names(), ", "))
+	}
+
+	if err := balancer.ValidateOptions(c.Strategy, c.StrategyOptions); err != nil {
+		return fmt.Errorf("strategy_options: %w", err)
+	}
+
+	if c.StickySession != nil {
+		if err := c.StickySession.Validate(); err != nil {
+			return fmt.Errorf("sticky_session: %w", err)
+		}
+	}
+
+	if c.FailoverThreshold < 0 || c.FailoverThreshold > 1 {
+		return errors.New("failover_threshold must be between 0 and 1")
+	}
+
+	if c.PanicThreshold < 0 || c.PanicThreshold > 1 {
+		return errors.New("panic_threshold must be between 0 and 1")
+	}
+
+	if c.Locality != nil {
+		if err := c.Locality.Validate(); err != nil {
+			return fmt.Errorf("locality: %w", err)
+		}
+	}
+
+	if c.SlowStart != nil {
+		if err := c.SlowStart.Validate(); err != nil {
+			return fmt.Errorf("slow_start: %w", err)
+		}
 	}
 
 	if len(c.Servers) == 0 {
 		return errors.New("at least one server is required")
 	}
 
+	if c.OutlierDetection != nil {
+		if err := c.OutlierDetection.Validate(); err != nil {
+			return fmt.Errorf("outlier_detection: %w", err)
+		}
+	}
+
+	if c.CircuitBreaker != nil {
+		if err := c.CircuitBreaker.Validate(); err != nil {
+			return fmt.Errorf("circuit_breaker: %w", err)
+		}
+	}
+
+	if err := c.HealthCheck.Validate(); err != nil {
+		return fmt.Errorf("health_check: %w", err)
+	}
+
+	if c.HealthCheck.Address != "" {
+		return errors.New("health_check: address can only be set per backend")
+	}
+
 	for i, server := range c.Servers {
 		if server.URL == "" {
 			return fmt.Errorf("server %d: URL is required", i)
@@ -97,7 +354,286 @@ func (c *Config) Validate() error {
 		if server.Weight < 1 {
 			return fmt.Errorf("server %d: weight must be at least 1", i)
 		}
+		if server.Priority < 0 {
+			return fmt.Errorf("server %d: priority must not be negative", i)
+		}
+		if server.HealthCheck != nil {
+			if err := server.HealthCheck.Validate(); err != nil {
+				return fmt.Errorf("server %d: health_check: %w", i, err)
+			}
+		}
 	}
 
 	return nil
 }
+
+func (s *StickySession) Validate() error {
+	cookie := http.Cookie{Name: s.CookieName, Value: "x"}
+	if err := cookie.Valid(); err != nil {
+		return fmt.Errorf("invalid cookie_name %q", s.CookieName)
+	}
+
+	if s.TTL < 1 {
+		return errors.New("ttl must be at least 1 second")
+	}
+
+	if len(s.SigningKey) < MinStickyKeyLength {
+		return fmt.Errorf("signing_key must be at least %d characters", MinStickyKeyLength)
+	}
+
+	return nil
+}
+
+func (l *Locality) Validate() error {
+	if l.Zone == "" {
+		return errors.New("zone is required")
+	}
+
+	if l.OverprovisioningFactor < 1 {
+		return errors.New("overprovisioning_factor must be at least 1")
+	}
+
+	if l.ConnectionsPerWeight < 0 {
+		return errors.New("connections_per_weight must not be negative")
+	}
+
+	return nil
+}
+
+func (s *SlowStart) Validate() error {
+	if s.Window < 1 {
+		return errors.New("window must be at least 1 second")
+	}
+
+	if s.Aggression <= 0 {
+		return errors.New("aggression must be positive")
+	}
+
+	if s.MinWeightPercent < 0 || s.MinWeightPercent > 100 {
+		return errors.New("min_weight_percent must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func (o *OutlierDetection) Validate() error {
+	if o.Consecutive5xx < 1 {
+		return errors.New("consecutive_5xx must be at least 1")
+	}
+
+	if o.ConsecutiveGatewayErrors < 0 {
+		return errors.New("consecutive_gateway_errors must not be negative")
+	}
+
+	if o.BaseEjectionTime < 1 {
+		return errors.New("base_ejection_time must be at least 1 second")
+	}
+
+	if o.MaxEjectionTime < o.BaseEjectionTime {
+		return errors.New("max_ejection_time must not be less than base_ejection_time")
+	}
+
+	if o.MaxEjectionPercent <= 0 || o.MaxEjectionPercent > 100 {
+		return errors.New("max_ejection_percent must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func (o *OutlierDetection) Config() server.OutlierConfig {
+	return server.OutlierConfig{
+		Consecutive5xx:           o.Consecutive5xx,
+		ConsecutiveGatewayErrors: o.ConsecutiveGatewayErrors,
+		BaseEjectionTime:         time.Duration(o.BaseEjectionTime) * time.Second,
+		MaxEjectionTime:          time.Duration(o.MaxEjectionTime) * time.Second,
+		MaxEjectionPercent:       o.MaxEjectionPercent,
+		UnejectOnHealthCheck:     o.UnejectOnHealthCheck,
+	}
+}
+
+func (b *CircuitBreaker) Validate() error {
+	if b.ConsecutiveFailures < 1 {
+		return errors.New("consecutive_failures must be at least 1")
+	}
+
+	if b.ErrorRate <= 0 || b.ErrorRate > 1 {
+		return errors.New("error_rate must be between 0 and 1")
+	}
+
+	if b.MinRequests < 1 {
+		return errors.New("min_requests must be at least 1")
+	}
+
+	if b.Window < 1 {
+		return errors.New("window must be at least 1 second")
+	}
+
+	if b.OpenTimeout < 1 {
+		return errors.New("open_timeout must be at least 1 second")
+	}
+
+	if b.HalfOpenRequests < 1 {
+		return errors.New("half_open_requests must be at least 1")
+	}
+
+	return nil
+}
+
+func (b *CircuitBreaker) Config() domain.BreakerConfig {
+	return domain.BreakerConfig{
+		ConsecutiveFailures: b.ConsecutiveFailures,
+		ErrorRate:           b.ErrorRate,
+		MinRequests:         b.MinRequests,
+		Window:              time.Duration(b.Window) * time.Second,
+		OpenTimeout:         time.Duration(b.OpenTimeout) * time.Second,
+		HalfOpenRequests:    b.HalfOpenRequests,
+	}
+}
+
+func (h *HealthCheck) applyDefaults() {
+	if h.Type == "" {
+		h.Type = HealthCheckTCP
+	}
+	if h.Timeout == 0 {
+		h.Timeout = int(server.DefaultProbeTimeout / time.Second)
+	}
+}
+
+// merge returns the check for a backend: every field set in override
+// replaces the global one, and headers are merged by name.
+func (h HealthCheck) merge(override *HealthCheck) HealthCheck {
+	if override == nil {
+		return h
+	}
+
+	merged := h
+	if override.Type != "" {
+		merged.Type = override.Type
+	}
+	if override.Path != "" {
+		merged.Path = override.Path
+	}
+	if override.Method != "" {
+		merged.Method = override.Method
+	}
+	if override.Host != "" {
+		merged.Host = override.Host
+	}
+	if len(override.ExpectedStatus) > 0 {
+		merged.ExpectedStatus = override.ExpectedStatus
+	}
+	if override.Body != "" || override.BodyRegex != "" {
+		merged.Body = override.Body
+		merged.BodyRegex = override.BodyRegex
+	}
+	if override.Service != "" {
+		merged.Service = override.Service
+	}
+	if override.CAFile != "" {
+		merged.CAFile = override.CAFile
+	}
+	if override.ServerName != "" {
+		merged.ServerName = override.ServerName
+	}
+	if override.CertExpiryWarning != 0 {
+		merged.CertExpiryWarning = override.CertExpiryWarning
+	}
+	if override.CertExpiryFailure != 0 {
+		merged.CertExpiryFailure = override.CertExpiryFailure
+	}
+	if len(override.Command) > 0 {
+		merged.Command = override.Command
+	}
+	if override.Address != "" || override.Port != 0 {
+		merged.Address = override.Address
+		merged.Port = override.Port
+	}
+	if override.Timeout != 0 {
+		merged.Timeout = override.Timeout
+	}
+
+	if len(override.Headers) > 0 {
+		merged.Headers = make(map[string]string, len(h.Headers)+len(override.Headers))
+		for name, value := range h.Headers {
+			merged.Headers[name] = value
+		}
+		for name, value := range override.Headers {
+			merged.Headers[name] = value
+		}
+	}
+
+	return merged
+}
+
+func (h *HealthCheck) Validate() error {
+	if h.Timeout < 1 {
+		return errors.New("timeout must be at least 1 second")
+	}
+
+	if h.Port < 0 || h.Port > 65535 {
+		return errors.New("port must be between 1 and 65535")
+	}
+
+	if h.Address != "" {
+		if h.Port != 0 {
+			return errors.New("address and port are mutually exclusive")
+		}
+
+		host, port, err := net.SplitHostPort(h.Address)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %w", h.Address, err)
+		}
+		if n, err := strconv.Atoi(port); host == "" || err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid address %q: want host:port", h.Address)
+		}
+	}
+
+	if h.CertExpiryWarning < 0 || h.CertExpiryFailure < 0 {
+		return errors.New("cert_expiry_warning and cert_expiry_failure must not be negative")
+	}
+
+	if h.CertExpiryWarning > 0 && h.CertExpiryWarning <= h.CertExpiryFailure {
+		return errors.New("cert_expiry_warning must be greater than cert_expiry_failure")
+	}
+
+	switch h.Type {
+	case HealthCheckTCP, HealthCheckGRPC, HealthCheckTLS:
+	case HealthCheckHTTP:
+		return h.HTTPProbeConfig().Validate()
+	case HealthCheckExec:
+		if len(h.Command) == 0 || h.Command[0] == "" {
+			return errors.New("command is required")
+		}
+	default:
+		return fmt.Errorf("unknown type %q (valid: %s, %s, %s, %s, %s)",
+			h.Type, HealthCheckTCP, HealthCheckHTTP, HealthCheckGRPC, HealthCheckTLS, HealthCheckExec)
+	}
+
+	return nil
+}
+
+// HTTPProbeConfig returns the settings of an http check.
+func (h *HealthCheck) HTTPProbeConfig() server.HTTPProbeConfig {
+	return server.HTTPProbeConfig{
+		Path:           h.Path,
+		Method:         h.Method,
+		Headers:        h.Headers,
+		Host:           h.Host,
+		ExpectedStatus: h.ExpectedStatus,
+		Body:           h.Body,
+		BodyRegex:      h.BodyRegex,
+		Timeout:        time.Duration(h.Timeout) * time.Second,
+	}
+}
+
+// TargetAddress returns the host:port to probe instead of the backend URL
+// host, or "" to probe the URL host.
+func (h *HealthCheck) TargetAddress(backend *url.URL) string {
+	if h.Address != "" {
+		return h.Address
+	}
+	if h.Port != 0 {
+		return net.JoinHostPort(backend.Hostname(), strconv.Itoa(h.Port))
+	}
+	return ""
+}
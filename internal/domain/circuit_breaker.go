@@ -0,0 +1,265 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+const breakerBuckets = 10
+
+type BreakerConfig struct {
+	ConsecutiveFailures int
+	ErrorRate           float64
+	MinRequests         int
+	Window              time.Duration
+	OpenTimeout         time.Duration
+	HalfOpenRequests    int
+}
+
+type breakerBucket struct {
+	epoch     int64
+	successes int
+	failures  int
+}
+
+// circuitBreaker trips on consecutive failures or on the failure rate over a
+// rolling window, stays open for the open timeout and then lets a limited
+// number of trial requests through. The listener runs without the breaker
+// lock held whenever the state or the ability to take traffic changes.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	cfg         *BreakerConfig
+	state       BreakerState
+	buckets     [breakerBuckets]breakerBucket
+	consecutive int
+	inFlight    int
+	successes   int
+	opened      int
+	trips       uint64
+	listener    func(from, to BreakerState)
+}
+
+func (b *circuitBreaker) configure(cfg BreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.cfg = &cfg
+	b.state = BreakerClosed
+	b.reset()
+}
+
+func (b *circuitBreaker) setListener(fn func(from, to BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listener = fn
+}
+
+func (b *circuitBreaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *circuitBreaker) tripCount() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.trips
+}
+
+func (b *circuitBreaker) takesTraffic() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		return b.inFlight < b.cfg.HalfOpenRequests
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+
+	if b.cfg == nil || b.state == BreakerClosed {
+		b.mu.Unlock()
+		return true
+	}
+
+	if b.state == BreakerOpen || b.inFlight >= b.cfg.HalfOpenRequests {
+		b.mu.Unlock()
+		return false
+	}
+
+	b.inFlight++
+	saturated := b.inFlight == b.cfg.HalfOpenRequests
+	b.mu.Unlock()
+
+	if saturated {
+		b.notify(BreakerHalfOpen, BreakerHalfOpen)
+	}
+	return true
+}
+
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	b.mu.Lock()
+
+	if b.cfg == nil {
+		b.mu.Unlock()
+		return
+	}
+
+	from := b.state
+	wasSaturated := from == BreakerHalfOpen && b.inFlight >= b.cfg.HalfOpenRequests
+
+	switch b.state {
+	case BreakerClosed:
+		b.add(success, now)
+		if b.shouldTrip(now) {
+			b.trip()
+		}
+	case BreakerHalfOpen:
+		b.inFlight = max(b.inFlight-1, 0)
+		if !success {
+			b.trip()
+			break
+		}
+
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.state = BreakerClosed
+			b.reset()
+		}
+	}
+
+	to := b.state
+	changed := from != to || (wasSaturated && b.inFlight < b.cfg.HalfOpenRequests)
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(from, to)
+	}
+}
+
+// release frees a half-open trial slot without counting a result.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+
+	if b.cfg == nil || b.state != BreakerHalfOpen || b.inFlight == 0 {
+		b.mu.Unlock()
+		return
+	}
+
+	wasSaturated := b.inFlight >= b.cfg.HalfOpenRequests
+	b.inFlight--
+	b.mu.Unlock()
+
+	if wasSaturated {
+		b.notify(BreakerHalfOpen, BreakerHalfOpen)
+	}
+}
+
+func (b *circuitBreaker) add(success bool, now time.Time) {
+	width := int64(b.cfg.Window / breakerBuckets)
+	epoch := now.UnixNano() / max(width, 1)
+	bucket := &b.buckets[epoch%breakerBuckets]
+
+	if bucket.epoch != epoch {
+		*bucket = breakerBucket{epoch: epoch}
+	}
+
+	if success {
+		bucket.successes++
+		b.consecutive = 0
+	} else {
+		bucket.failures++
+		b.consecutive++
+	}
+}
+
+func (b *circuitBreaker) shouldTrip(now time.Time) bool {
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		return true
+	}
+
+	if b.cfg.ErrorRate <= 0 {
+		return false
+	}
+
+	width := int64(b.cfg.Window / breakerBuckets)
+	oldest := now.UnixNano()/max(width, 1) - breakerBuckets
+
+	total, failures := 0, 0
+	for _, bucket := range b.buckets {
+		if bucket.epoch > oldest {
+			total += bucket.successes + bucket.failures
+			failures += bucket.failures
+		}
+	}
+
+	return total >= max(b.cfg.MinRequests, 1) && float64(failures)/float64(total) >= b.cfg.ErrorRate
+}
+
+func (b *circuitBreaker) trip() {
+	b.state = BreakerOpen
+	b.trips++
+	b.opened++
+	b.reset()
+
+	opened := b.opened
+	time.AfterFunc(b.cfg.OpenTimeout, func() {
+		b.halfOpen(opened)
+	})
+}
+
+func (b *circuitBreaker) halfOpen(opened int) {
+	b.mu.Lock()
+
+	if b.state != BreakerOpen || b.opened != opened {
+		b.mu.Unlock()
+		return
+	}
+
+	b.state = BreakerHalfOpen
+	b.mu.Unlock()
+
+	b.notify(BreakerOpen, BreakerHalfOpen)
+}
+
+func (b *circuitBreaker) reset() {
+	b.buckets = [breakerBuckets]breakerBucket{}
+	b.consecutive = 0
+	b.inFlight = 0
+	b.successes = 0
+}
+
+func (b *circuitBreaker) notify(from, to BreakerState) {
+	b.mu.Lock()
+	listener := b.listener
+	b.mu.Unlock()
+
+	if listener != nil {
+		listener(from, to)
+	}
+}
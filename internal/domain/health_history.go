@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"sync"
+	"time"
+)
+
+const DefaultHealthHistorySize = 20
+
+type HealthCheckRecord struct {
+	Time    time.Time
+	Latency time.Duration
+	Passed  bool
+	Error   string
+	Warning string
+}
+
+// healthHistory is a fixed-size ring of the most recent health checks.
+type healthHistory struct {
+	mu      sync.Mutex
+	records []HealthCheckRecord
+	next    int
+	full    bool
+}
+
+func (h *healthHistory) add(record HealthCheckRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.records == nil {
+		h.records = make([]HealthCheckRecord, DefaultHealthHistorySize)
+	}
+
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *healthHistory) snapshot() []HealthCheckRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]HealthCheckRecord(nil), h.records[:h.next]...)
+	}
+
+	out := make([]HealthCheckRecord, 0, len(h.records))
+	out = append(out, h.records[h.next:]...)
+	return append(out, h.records[:h.next]...)
+}
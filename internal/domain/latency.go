@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const DefaultLatencyDecay = 10 * time.Second
+
+// latencyEWMA is a peak-sensitive moving average: slower samples replace the
+// average outright, faster ones blend in, and the value decays toward zero
+// while the server is idle so it gets probed again.
+type latencyEWMA struct {
+	mu      sync.Mutex
+	decay   time.Duration
+	value   float64
+	stamp   time.Time
+	sampled bool
+}
+
+func (e *latencyEWMA) observe(rtt time.Duration, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	sample := float64(rtt)
+	if !e.sampled {
+		e.value = sample
+		e.stamp = now
+		e.sampled = true
+		return
+	}
+
+	w := e.weight(now)
+	current := e.value * w
+
+	if sample > current {
+		e.value = sample
+	} else {
+		e.value = current + sample*(1-w)
+	}
+	e.stamp = now
+}
+
+func (e *latencyEWMA) get(now time.Time) (time.Duration, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.sampled {
+		return 0, false
+	}
+
+	return time.Duration(e.value * e.weight(now)), true
+}
+
+func (e *latencyEWMA) weight(now time.Time) float64 {
+	elapsed := now.Sub(e.stamp)
+	if elapsed <= 0 {
+		return 1
+	}
+	return math.Exp(-float64(elapsed) / float64(e.decay))
+}
+
+func (e *latencyEWMA) setDecay(decay time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.decay = decay
+}
@@ -1,14 +1,28 @@
 package domain
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type ServerPool struct {
-	servers        []*Server
-	mu             sync.RWMutex
-	healthyServers atomic.Value
+	servers           []*Server
+	mu                sync.RWMutex
+	healthyServers    atomic.Value
+	generation        atomic.Uint64
+	failoverThreshold float64
+	panicThreshold    float64
+	panicking         bool
+	onPanic           func(panicking bool)
+	onBreaker         func(server *Server, from, to BreakerState)
+	subsets           []poolSubset
+}
+
+type poolSubset struct {
+	pool   *ServerPool
+	filter func(*Server) bool
 }
 
 func NewServerPool() *ServerPool {
@@ -22,10 +36,35 @@ func NewServerPool() *ServerPool {
 func (p *ServerPool) AddServer(server *Server) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+
+	server.breaker.setListener(func(from, to BreakerState) {
+		p.breakerChanged(server, from, to)
+	})
+
 	p.servers = append(p.servers, server)
 	p.refreshHealthyCache()
 }
 
+func (p *ServerPool) breakerChanged(server *Server, from, to BreakerState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refreshHealthyCache()
+
+	if from != to && p.onBreaker != nil {
+		p.onBreaker(server, from, to)
+	}
+}
+
+// OnBreakerChange registers fn to be called on every circuit breaker state
+// change of a server in the pool. Like OnPanicChange it runs with the pool
+// locked.
+func (p *ServerPool) OnBreakerChange(fn func(server *Server, from, to BreakerState)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onBreaker = fn
+}
+
 func (p *ServerPool) GetServers() []*Server {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
@@ -39,14 +78,147 @@ func (p *ServerPool) GetHealthyServers() []*Server {
 	return p.healthyServers.Load().([]*Server)
 }
 
+// refreshHealthyCache publishes the servers that may receive traffic. Tiers
+// are taken in priority order, lowest first, and a lower tier is added only
+// while the tiers above it are below the failover threshold. Below the panic
+// threshold health checks are no longer trusted and every server is used.
+// Subsets are refreshed first, so a reader that sees the new generation also
+// sees the new subsets.
 func (p *ServerPool) refreshHealthyCache() {
-	healthy := make([]*Server, 0, len(p.servers))
+	healthy := p.routableServers()
+
+	for _, subset := range p.subsets {
+		subset.refresh(p.servers, healthy)
+	}
+
+	p.healthyServers.Store(healthy)
+	p.generation.Add(1)
+}
+
+func (p *ServerPool) routableServers() []*Server {
+	alive := 0
 	for _, s := range p.servers {
-		if s.IsAlive() {
-			healthy = append(healthy, s)
+		if s.IsAvailable() {
+			alive++
 		}
 	}
-	p.healthyServers.Store(healthy)
+
+	panicking := len(p.servers) > 0 && float64(alive)/float64(len(p.servers)) < p.panicThreshold
+	if panicking != p.panicking {
+		p.panicking = panicking
+		if p.onPanic != nil {
+			p.onPanic(panicking)
+		}
+	}
+
+	// An open circuit would reject the request anyway, so panic mode only
+	// brings back servers whose breaker still takes traffic.
+	if panicking {
+		all := make([]*Server, 0, len(p.servers))
+		for _, s := range p.servers {
+			if s.breaker.takesTraffic() {
+				all = append(all, s)
+			}
+		}
+		return all
+	}
+
+	tiers := make(map[int][]*Server)
+	for _, s := range p.servers {
+		tiers[s.Priority] = append(tiers[s.Priority], s)
+	}
+
+	priorities := make([]int, 0, len(tiers))
+	for priority := range tiers {
+		priorities = append(priorities, priority)
+	}
+	sort.Ints(priorities)
+
+	healthy := make([]*Server, 0, len(p.servers))
+	for _, priority := range priorities {
+		tier := tiers[priority]
+
+		alive := 0
+		for _, s := range tier {
+			if s.IsAvailable() {
+				healthy = append(healthy, s)
+				alive++
+			}
+		}
+
+		if alive > 0 && float64(alive)/float64(len(tier)) >= p.failoverThreshold {
+			break
+		}
+	}
+
+	return healthy
+}
+
+func (p *ServerPool) SetPanicThreshold(threshold float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.panicThreshold = threshold
+	p.refreshHealthyCache()
+}
+
+// OnPanicChange registers fn to be called whenever the pool enters or leaves
+// panic mode. It runs with the pool locked and must not call back into it.
+func (p *ServerPool) OnPanicChange(fn func(panicking bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onPanic = fn
+}
+
+func (p *ServerPool) InPanic() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.panicking
+}
+
+// Subset returns a read-only view of the servers matching filter. The view
+// follows every health, weight and tier change of the parent pool, so any
+// strategy can run against it; changes must be made through the parent.
+func (p *ServerPool) Subset(filter func(*Server) bool) *ServerPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	subset := poolSubset{pool: NewServerPool(), filter: filter}
+	p.subsets = append(p.subsets, subset)
+	subset.refresh(p.servers, p.GetHealthyServers())
+
+	return subset.pool
+}
+
+func (s poolSubset) refresh(servers, healthy []*Server) {
+	s.pool.mu.Lock()
+	defer s.pool.mu.Unlock()
+
+	s.pool.servers = filterServers(servers, s.filter)
+	s.pool.healthyServers.Store(filterServers(healthy, s.filter))
+	s.pool.generation.Add(1)
+}
+
+func filterServers(servers []*Server, filter func(*Server) bool) []*Server {
+	result := make([]*Server, 0, len(servers))
+	for _, s := range servers {
+		if filter(s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func (p *ServerPool) SetFailoverThreshold(threshold float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failoverThreshold = threshold
+	p.refreshHealthyCache()
+}
+
+func (p *ServerPool) Generation() uint64 {
+	return p.generation.Load()
 }
 
 func (p *ServerPool) SetServerStatus(server *Server, alive bool) {
@@ -61,6 +233,73 @@ func (p *ServerPool) SetServerStatus(server *Server, alive bool) {
 	p.refreshHealthyCache()
 }
 
+func (p *ServerPool) SetServerWeight(server *Server, weight int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if server.GetWeight() == weight {
+		return
+	}
+
+	server.SetWeight(weight)
+	p.refreshHealthyCache()
+}
+
+// EjectServer takes the server out of rotation until the given time, unless
+// that would leave more than maxPercent of the pool ejected. The first
+// ejection is always allowed so a single bad server in a small pool can go.
+func (p *ServerPool) EjectServer(server *Server, until time.Time, maxPercent float64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if server.IsEjected() {
+		return false
+	}
+
+	ejected := 0
+	for _, s := range p.servers {
+		if s.IsEjected() {
+			ejected++
+		}
+	}
+
+	if ejected > 0 && float64(ejected+1)*100 > maxPercent*float64(len(p.servers)) {
+		return false
+	}
+
+	server.ejectedTill.Store(until.UnixNano())
+	p.refreshHealthyCache()
+
+	return true
+}
+
+func (p *ServerPool) UnejectServer(server *Server) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !server.IsEjected() {
+		return false
+	}
+
+	server.ejectedTill.Store(0)
+	p.refreshHealthyCache()
+
+	return true
+}
+
+func (p *ServerPool) EjectedCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	count := 0
+	for _, s := range p.servers {
+		if s.IsEjected() {
+			count++
+		}
+	}
+	return count
+}
+
 func (p *ServerPool) MarkServerStatus(serverURL string, alive bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -4,14 +4,27 @@ import (
 	"net/url"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Server struct {
-	URL         *url.URL
-	Weight      int
+	URL      *url.URL
+	Priority int
+	Zone     string
+	Region   string
+	// HealthCheckAddress is the host:port probed by the health checker
+	// instead of the URL host, e.g. a separate management port.
+	HealthCheckAddress string
+
+	weight      atomic.Int64
 	alive       bool
 	mu          sync.RWMutex
 	connections atomic.Int64
+	latency     latencyEWMA
+	slowStart   slowStart
+	ejectedTill atomic.Int64
+	breaker     circuitBreaker
+	history     healthHistory
 }
 
 func NewServer(rawURL string, weight int) (*Server, error) {
@@ -20,20 +33,51 @@ func NewServer(rawURL string, weight int) (*Server, error) {
 		return nil, err
 	}
 
+	server := &Server{
+		URL:     parsedURL,
+		alive:   true,
+		latency: latencyEWMA{decay: DefaultLatencyDecay},
+	}
+	server.SetWeight(weight)
+
+	return server, nil
+}
+
+func (s *Server) SetWeight(weight int) {
 	if weight < 1 {
 		weight = 1
 	}
+	s.weight.Store(int64(weight))
+}
 
-	return &Server{
-		URL:    parsedURL,
-		Weight: weight,
-		alive:  true,
-	}, nil
+func (s *Server) GetWeight() int {
+	return int(s.weight.Load())
+}
+
+func (s *Server) EffectiveWeight() float64 {
+	factor, _ := s.slowStart.factor(time.Now())
+	return float64(s.GetWeight()) * factor
+}
+
+func (s *Server) IsWarming() bool {
+	_, warming := s.slowStart.factor(time.Now())
+	return warming
+}
+
+func (s *Server) SetSlowStart(window time.Duration, aggression, minWeightPercent float64) {
+	if aggression <= 0 {
+		aggression = DefaultSlowStartAggression
+	}
+	s.slowStart.configure(window, aggression, minWeightPercent)
 }
 
 func (s *Server) SetAlive(alive bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+
+	if alive && !s.alive {
+		s.slowStart.begin(time.Now())
+	}
 	s.alive = alive
 }
 
@@ -43,6 +87,62 @@ func (s *Server) IsAlive() bool {
 	return s.alive
 }
 
+// IsEjected reports whether passive outlier detection has taken the server
+// out of rotation; ejection is independent of the active health state.
+func (s *Server) IsEjected() bool {
+	return s.ejectedTill.Load() != 0
+}
+
+func (s *Server) EjectedUntil() time.Time {
+	if till := s.ejectedTill.Load(); till != 0 {
+		return time.Unix(0, till)
+	}
+	return time.Time{}
+}
+
+// IsAvailable reports whether the server may receive traffic: it passes
+// health checks, is not ejected and its circuit breaker lets requests in.
+func (s *Server) IsAvailable() bool {
+	return s.IsAlive() && !s.IsEjected() && s.breaker.takesTraffic()
+}
+
+func (s *Server) SetCircuitBreaker(cfg BreakerConfig) {
+	s.breaker.configure(cfg)
+}
+
+func (s *Server) BreakerState() BreakerState {
+	return s.breaker.current()
+}
+
+func (s *Server) BreakerTrips() uint64 {
+	return s.breaker.tripCount()
+}
+
+// AllowRequest reserves a trial slot while the circuit is half-open. Every
+// allowed request must be followed by RecordResult or ReleaseRequest.
+func (s *Server) AllowRequest() bool {
+	return s.breaker.allow()
+}
+
+func (s *Server) RecordResult(success bool) {
+	s.breaker.record(success, time.Now())
+}
+
+// ReleaseRequest ends an allowed request that has no result, e.g. because
+// the client went away.
+func (s *Server) ReleaseRequest() {
+	s.breaker.release()
+}
+
+func (s *Server) RecordHealthCheck(record HealthCheckRecord) {
+	s.history.add(record)
+}
+
+// HealthHistory returns the most recent health checks, oldest first.
+func (s *Server) HealthHistory() []HealthCheckRecord {
+	return s.history.snapshot()
+}
+
 func (s *Server) IncrementConnections() {
 	s.connections.Add(1)
 }
@@ -54,3 +154,18 @@ func (s *Server) DecrementConnections() {
 func (s *Server) GetConnections() int64 {
 	return s.connections.Load()
 }
+
+func (s *Server) ObserveLatency(rtt time.Duration) {
+	s.latency.observe(rtt, time.Now())
+}
+
+func (s *Server) LatencyEWMA() (time.Duration, bool) {
+	return s.latency.get(time.Now())
+}
+
+func (s *Server) SetLatencyDecay(decay time.Duration) {
+	if decay <= 0 {
+		decay = DefaultLatencyDecay
+	}
+	s.latency.setDecay(decay)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSlowStartAggression = 1.0
+	DefaultSlowStartMinPercent = 10.0
+)
+
+// slowStart ramps a recovered server from a fraction of its weight back to
+// the full weight over the window. Aggression above 1 ramps faster early on,
+// below 1 holds the weight low for longer.
+type slowStart struct {
+	mu         sync.Mutex
+	window     time.Duration
+	aggression float64
+	minPercent float64
+	upSince    time.Time
+}
+
+func (s *slowStart) configure(window time.Duration, aggression, minPercent float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.window = window
+	s.aggression = aggression
+	s.minPercent = minPercent
+}
+
+func (s *slowStart) begin(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.window > 0 {
+		s.upSince = now
+	}
+}
+
+func (s *slowStart) factor(now time.Time) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.upSince.IsZero() {
+		return 1, false
+	}
+
+	elapsed := now.Sub(s.upSince)
+	if elapsed >= s.window {
+		s.upSince = time.Time{}
+		return 1, false
+	}
+
+	progress := math.Pow(max(float64(elapsed), 0)/float64(s.window), 1/s.aggression)
+	return max(progress, s.minPercent/100), true
+}
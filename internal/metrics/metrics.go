@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type Counter struct {
+	value atomic.Uint64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+type series struct {
+	labels string
+	value  func() float64
+	metric any
+}
+
+type family struct {
+	name   string
+	help   string
+	kind   string
+	series []*series
+}
+
+// Registry holds metric families and renders them in the Prometheus text
+// exposition format. Labels are given as alternating name/value pairs.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	s := r.register(name, help, "counter", labels, func() *series {
+		c := &Counter{}
+		return &series{value: func() float64 { return float64(c.Value()) }, metric: c}
+	})
+	return s.metric.(*Counter)
+}
+
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	s := r.register(name, help, "gauge", labels, func() *series {
+		g := &Gauge{}
+		return &series{value: g.Value, metric: g}
+	})
+	return s.metric.(*Gauge)
+}
+
+func (r *Registry) CounterFunc(name, help string, fn func() float64, labels ...string) {
+	r.register(name, help, "counter", labels, func() *series {
+		return &series{value: fn}
+	})
+}
+
+func (r *Registry) GaugeFunc(name, help string, fn func() float64, labels ...string) {
+	r.register(name, help, "gauge", labels, func() *series {
+		return &series{value: fn}
+	})
+}
+
+func (r *Registry) register(name, help, kind string, labels []string, create func() *series) *series {
+	if len(labels)%2 != 0 {
+		panic(fmt.Sprintf("metrics: odd number of label arguments for %s", name))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: kind}
+		r.families[name] = f
+	} else if f.kind != kind {
+		panic(fmt.Sprintf("metrics: %s registered as %s and %s", name, f.kind, kind))
+	}
+
+	key := formatLabels(labels)
+	for _, s := range f.series {
+		if s.labels == key {
+			return s
+		}
+	}
+
+	s := create()
+	s.labels = key
+	f.series = append(f.series, s)
+
+	return s
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		copied := *f
+		copied.series = append([]*series(nil), f.series...)
+		families = append(families, copied)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	var b strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+
+		for _, s := range f.series {
+			fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, strconv.FormatFloat(s.value(), 'g', -1, 64))
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"janus/internal/domain"
+)
+
+type AdminHandler struct {
+	pool *domain.ServerPool
+	mux  *http.ServeMux
+}
+
+type ServerStatus struct {
+	URL             string  `json:"url"`
+	Alive           bool    `json:"alive"`
+	Routable        bool    `json:"routable"`
+	Ejected         bool    `json:"ejected"`
+	CircuitBreaker  string  `json:"circuit_breaker"`
+	Weight          int     `json:"weight"`
+	EffectiveWeight float64 `json:"effective_weight"`
+	Warming         bool    `json:"warming"`
+	Priority        int     `json:"priority"`
+	Zone            string  `json:"zone,omitempty"`
+	Region          string  `json:"region,omitempty"`
+	CheckAddress    string  `json:"health_check_address,omitempty"`
+	Connections     int64   `json:"connections"`
+	LatencyMs       float64 `json:"latency_ms"`
+}
+
+type PoolStatus struct {
+	Panic   bool           `json:"panic"`
+	Servers []ServerStatus `json:"servers"`
+}
+
+type HealthCheckStatus struct {
+	Time      time.Time `json:"time"`
+	LatencyMs float64   `json:"latency_ms"`
+	Passed    bool      `json:"passed"`
+	Error     string    `json:"error,omitempty"`
+	Warning   string    `json:"warning,omitempty"`
+}
+
+type ServerHistory struct {
+	URL    string              `json:"url"`
+	Alive  bool                `json:"alive"`
+	Flips  int                 `json:"flips"`
+	Checks []HealthCheckStatus `json:"checks"`
+}
+
+func NewAdminHandler(pool *domain.ServerPool) *AdminHandler {
+	h := &AdminHandler{
+		pool: pool,
+		mux:  http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET /status", h.handleStatus)
+	h.mux.HandleFunc("GET /health/history", h.handleHistory)
+
+	return h
+}
+
+func (h *AdminHandler) Handle(pattern string, handler http.Handler) {
+	h.mux.Handle(pattern, handler)
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *AdminHandler) Status() PoolStatus {
+	routable := make(map[*domain.Server]bool)
+	for _, s := range h.pool.GetHealthyServers() {
+		routable[s] = true
+	}
+
+	servers := h.pool.GetServers()
+	status := PoolStatus{
+		Panic:   h.pool.InPanic(),
+		Servers: make([]ServerStatus, 0, len(servers)),
+	}
+
+	for _, s := range servers {
+		latency, _ := s.LatencyEWMA()
+
+		status.Servers = append(status.Servers, ServerStatus{
+			URL:             s.URL.String(),
+			Alive:           s.IsAlive(),
+			Routable:        routable[s],
+			Ejected:         s.IsEjected(),
+			CircuitBreaker:  s.BreakerState().String(),
+			Weight:          s.GetWeight(),
+			EffectiveWeight: s.EffectiveWeight(),
+			Warming:         s.IsWarming(),
+			Priority:        s.Priority,
+			Zone:            s.Zone,
+			Region:          s.Region,
+			CheckAddress:    s.HealthCheckAddress,
+			Connections:     s.GetConnections(),
+			LatencyMs:       float64(latency) / float64(time.Millisecond),
+		})
+	}
+
+	return status
+}
+
+func (h *AdminHandler) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.Status())
+}
+
+// History returns the recent health checks of every server, oldest first.
+func (h *AdminHandler) History() []ServerHistory {
+	servers := h.pool.GetServers()
+	history := make([]ServerHistory, 0, len(servers))
+
+	for _, s := range servers {
+		history = append(history, serverHistory(s))
+	}
+
+	return history
+}
+
+func serverHistory(s *domain.Server) ServerHistory {
+	records := s.HealthHistory()
+	checks := make([]HealthCheckStatus, 0, len(records))
+
+	for _, r := range records {
+		checks = append(checks, HealthCheckStatus{
+			Time:      r.Time,
+			LatencyMs: float64(r.Latency) / float64(time.Millisecond),
+			Passed:    r.Passed,
+			Error:     r.Error,
+			Warning:   r.Warning,
+		})
+	}
+
+	return ServerHistory{
+		URL:    s.URL.String(),
+		Alive:  s.IsAlive(),
+		Flips:  countFlips(records),
+		Checks: checks,
+	}
+}
+
+func (h *AdminHandler) handleHistory(w http.ResponseWriter, r *http.Request) {
+	var body any = h.History()
+
+	if target := r.URL.Query().Get("server"); target != "" {
+		body = nil
+		for _, s := range h.pool.GetServers() {
+			if s.URL.String() == target {
+				body = serverHistory(s)
+				break
+			}
+		}
+
+		if body == nil {
+			http.Error(w, "unknown server", http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
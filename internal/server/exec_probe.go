@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"janus/internal/domain"
+)
+
+const maxExecOutput = 1 << 10
+
+type ExecProbeConfig struct {
+	// Command is the program and its arguments. "{host}", "{port}" and
+	// "{url}" are replaced with the backend's values.
+	Command []string
+	Timeout time.Duration
+}
+
+// ExecProbe runs a local command and treats exit code 0 as healthy. The
+// backend is also passed in JANUS_BACKEND_HOST, JANUS_BACKEND_PORT and
+// JANUS_BACKEND_URL.
+type ExecProbe struct {
+	command []string
+	timeout time.Duration
+}
+
+func NewExecProbe(cfg ExecProbeConfig) (*ExecProbe, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, errors.New("command is required")
+	}
+
+	if _, err := exec.LookPath(cfg.Command[0]); err != nil {
+		return nil, fmt.Errorf("command: %w", err)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+
+	return &ExecProbe{command: cfg.Command, timeout: timeout}, nil
+}
+
+func (p *ExecProbe) Check(ctx context.Context, server *domain.Server) error {
+	host, port, _ := net.SplitHostPort(dialAddress(server))
+	replacer := strings.NewReplacer("{host}", host, "{port}", port, "{url}", server.URL.String())
+
+	args := make([]string, len(p.command))
+	for i, arg := range p.command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var output cappedBuffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"JANUS_BACKEND_HOST="+host,
+		"JANUS_BACKEND_PORT="+port,
+		"JANUS_BACKEND_URL="+server.URL.String(),
+	)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Children that inherited the output pipes must not keep Wait blocked
+	// after the command is killed.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command timed out after %v", p.timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// cappedBuffer keeps the first maxExecOutput bytes written to it and
+// discards the rest.
+type cappedBuffer struct {
+	data []byte
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxExecOutput - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return string(b.data)
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"janus/internal/domain"
+)
+
+const grpcHealthPath = "/grpc.health.v1.Health/Check"
+
+// grpcServing is HealthCheckResponse.ServingStatus SERVING.
+const grpcServing = 1
+
+var grpcStatusNames = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+type GRPCProbeConfig struct {
+	Service   string
+	Timeout   time.Duration
+	TLSConfig *tls.Config
+}
+
+// GRPCProbe calls grpc.health.v1.Health/Check over HTTP/2: cleartext (h2c)
+// for http:// backends and TLS for https:// ones. The protobuf messages are
+// small enough to encode by hand.
+type GRPCProbe struct {
+	request []byte
+	client  *http.Client
+}
+
+func NewGRPCProbe(cfg GRPCProbeConfig) *GRPCProbe {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &GRPCProbe{
+		request: encodeGRPCFrame(encodeHealthCheckRequest(cfg.Service)),
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Protocols:         protocols,
+				DialContext:       probeDialer(timeout),
+				TLSClientConfig:   cfg.TLSConfig,
+				DisableKeepAlives: true,
+			},
+		},
+	}
+}
+
+func (p *GRPCProbe) Check(ctx context.Context, server *domain.Server) error {
+	ctx = withDialAddress(ctx, server)
+	target := *server.URL
+	target.Path = grpcHealthPath
+	target.RawPath = ""
+	target.RawQuery = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(p.request))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("User-Agent", "janus-health-check")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Errors come either as trailers or, without a body, in the headers.
+	status := resp.Trailer.Get("Grpc-Status")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+	}
+	if status != "0" {
+		message := resp.Trailer.Get("Grpc-Message")
+		if message == "" {
+			message = resp.Header.Get("Grpc-Message")
+		}
+		return fmt.Errorf("grpc status %s: %s", status, message)
+	}
+
+	message, err := decodeGRPCFrame(body)
+	if err != nil {
+		return err
+	}
+
+	serving, err := decodeHealthCheckResponse(message)
+	if err != nil {
+		return err
+	}
+
+	if serving != grpcServing {
+		name, ok := grpcStatusNames[serving]
+		if !ok {
+			name = fmt.Sprintf("status %d", serving)
+		}
+		return fmt.Errorf("service is %s", name)
+	}
+
+	return nil
+}
+
+func encodeHealthCheckRequest(service string) []byte {
+	if service == "" {
+		return nil
+	}
+
+	// Field 1 (service), wire type 2 (length-delimited).
+	msg := []byte{0x0a}
+	msg = binary.AppendUvarint(msg, uint64(len(service)))
+	return append(msg, service...)
+}
+
+func decodeHealthCheckResponse(msg []byte) (uint64, error) {
+	var status uint64
+
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return 0, errors.New("malformed health check response")
+		}
+		msg = msg[n:]
+
+		field, wireType := key>>3, key&0x7
+
+		switch wireType {
+		case 0:
+			value, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return 0, errors.New("malformed health check response")
+			}
+			msg = msg[n:]
+
+			if field == 1 {
+				status = value
+			}
+		case 2:
+			length, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < length {
+				return 0, errors.New("malformed health check response")
+			}
+			msg = msg[n+int(length):]
+		default:
+			return 0, fmt.Errorf("unexpected wire type %d in health check response", wireType)
+		}
+	}
+
+	return status, nil
+}
+
+func encodeGRPCFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+func decodeGRPCFrame(body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, errors.New("empty grpc response")
+	}
+
+	if body[0] != 0 {
+		return nil, errors.New("compressed grpc responses are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < length {
+		return nil, errors.New("truncated grpc response")
+	}
+
+	return body[5 : 5+length], nil
+}
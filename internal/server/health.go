@@ -2,92 +2,330 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"net"
+	"math/rand/v2"
+	"strings"
+	"sync"
 	"time"
 
 	"janus/internal/domain"
+	"janus/internal/metrics"
+)
+
+const (
+	DefaultHealthCheckJitter      = 0.1
+	DefaultHealthCheckConcurrency = 64
 )
 
 type HealthChecker struct {
-	pool     *domain.ServerPool
-	interval time.Duration
-	timeout  time.Duration
+	pool               *domain.ServerPool
+	interval           time.Duration
+	jitter             float64
+	workers            chan struct{}
+	probe              Probe
+	healthyThreshold   int
+	unhealthyThreshold int
+	registry           *metrics.Registry
+	outliers           *OutlierDetector
+	mu                 sync.RWMutex
+	probes             map[*domain.Server]Probe
+	streaks            map[*domain.Server]*streak
+	warnings           map[*domain.Server]string
+}
+
+// streak counts consecutive probe results of the same kind.
+type streak struct {
+	passing bool
+	count   int
 }
 
 func NewHealthChecker(pool *domain.ServerPool, interval time.Duration) *HealthChecker {
 	return &HealthChecker{
-		pool:     pool,
-		interval: interval,
-		timeout:  2 * time.Second,
+		pool:               pool,
+		interval:           interval,
+		jitter:             DefaultHealthCheckJitter,
+		workers:            make(chan struct{}, DefaultHealthCheckConcurrency),
+		probe:              NewTCPProbe(DefaultProbeTimeout),
+		healthyThreshold:   1,
+		unhealthyThreshold: 1,
+		probes:             make(map[*domain.Server]Probe),
+		streaks:            make(map[*domain.Server]*streak),
+		warnings:           make(map[*domain.Server]string),
 	}
 }
 
+// SetThresholds sets how many consecutive passing checks bring a server UP
+// and how many consecutive failures take it DOWN.
+func (h *HealthChecker) SetThresholds(healthy, unhealthy int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.healthyThreshold = max(healthy, 1)
+	h.unhealthyThreshold = max(unhealthy, 1)
+}
+
+// SetJitter spreads checks by randomizing every interval by up to the given
+// fraction in either direction. It must be called before Start.
+func (h *HealthChecker) SetJitter(fraction float64) {
+	h.jitter = min(max(fraction, 0), 1)
+}
+
+// SetConcurrency limits how many probes run at the same time. It must be
+// called before Start.
+func (h *HealthChecker) SetConcurrency(n int) {
+	h.workers = make(chan struct{}, max(n, 1))
+}
+
+// Instrument counts checks that could not run on schedule: "skipped" when
+// the previous probe of the server was still running, "late" when a probe
+// waited for a free worker for more than half the interval.
+func (h *HealthChecker) Instrument(registry *metrics.Registry) {
+	h.registry = registry
+}
+
+// SetOutlierDetector reports passing checks to the detector, which may end
+// ejections early. It must be called before Start.
+func (h *HealthChecker) SetOutlierDetector(detector *OutlierDetector) {
+	h.outliers = detector
+}
+
+func (h *HealthChecker) SetDefaultProbe(probe Probe) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.probe = probe
+}
+
+func (h *HealthChecker) SetProbe(server *domain.Server, probe Probe) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.probes[server] = probe
+}
+
 func (h *HealthChecker) Start(ctx context.Context) {
-	h.checkAll()
+	for _, server := range h.pool.GetServers() {
+		go h.run(ctx, server)
+	}
 
 	go func() {
-		ticker := time.NewTicker(h.interval)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				log.Println("[INFO] Health checker stopped")
-				return
-			case <-ticker.C:
-				h.checkAll()
-			}
-		}
+		<-ctx.Done()
+		log.Println("[INFO] Health checker stopped")
 	}()
 
-	log.Printf("[INFO] Health checker started (interval: %v)", h.interval)
+	log.Printf("[INFO] Health checker started (interval: %v, jitter: %.0f%%, concurrency: %d)",
+		h.interval, h.jitter*100, cap(h.workers))
 }
 
-func (h *HealthChecker) checkAll() {
-	servers := h.pool.GetServers()
+// run checks one server on its own schedule, so a server never has more
+// than one probe in flight. The first check lands at a random point within
+// the jitter window to keep servers from being probed in lockstep.
+func (h *HealthChecker) run(ctx context.Context, server *domain.Server) {
+	late, skipped := h.missedCounters(server)
 
-	for _, server := range servers {
-		go h.checkServer(server)
+	next := time.Now().Add(time.Duration(rand.Float64() * h.jitter * float64(h.interval)))
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case h.workers <- struct{}{}:
+		}
+
+		if time.Since(next) > h.interval/2 {
+			late.Inc()
+		}
+
+		h.checkServer(ctx, server)
+		<-h.workers
+
+		next = next.Add(h.nextInterval())
+		for now := time.Now(); next.Before(now); next = next.Add(h.interval) {
+			skipped.Inc()
+		}
+
+		timer.Reset(time.Until(next))
 	}
 }
 
-func (h *HealthChecker) checkServer(server *domain.Server) {
-	address := server.URL.Host
+func (h *HealthChecker) nextInterval() time.Duration {
+	return time.Duration(float64(h.interval) * (1 + h.jitter*(2*rand.Float64()-1)))
+}
 
-	if server.URL.Port() == "" {
-		if server.URL.Scheme == "https" {
-			address = address + ":443"
-		} else {
-			address = address + ":80"
-		}
+func (h *HealthChecker) missedCounters(server *domain.Server) (late, skipped *metrics.Counter) {
+	if h.registry == nil {
+		return &metrics.Counter{}, &metrics.Counter{}
 	}
 
-	conn, err := net.DialTimeout("tcp", address, h.timeout)
+	const name, help = "janus_health_checks_missed_total", "Number of health checks that did not run on schedule."
+	late = h.registry.Counter(name, help, "server", server.URL.String(), "reason", "late")
+	skipped = h.registry.Counter(name, help, "server", server.URL.String(), "reason", "skipped")
+	return late, skipped
+}
+
+func (h *HealthChecker) probeFor(server *domain.Server) Probe {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if probe, ok := h.probes[server]; ok {
+		return probe
+	}
+	return h.probe
+}
+
+func (h *HealthChecker) record(server *domain.Server, passing bool) (count, threshold int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.streaks[server]
+	if !ok || s.passing != passing {
+		s = &streak{passing: passing}
+		h.streaks[server] = s
+	}
+	s.count++
+
+	if passing {
+		return s.count, h.healthyThreshold
+	}
+	return s.count, h.unhealthyThreshold
+}
+
+// reportWarning logs a probe warning when it first appears or changes, and
+// when it clears.
+func (h *HealthChecker) reportWarning(server *domain.Server, warning *ProbeWarning) {
+	text := ""
+	if warning != nil {
+		text = warning.Error()
+	}
+
+	h.mu.Lock()
+	previous := h.warnings[server]
+	if text == "" {
+		delete(h.warnings, server)
+	} else {
+		h.warnings[server] = text
+	}
+	h.mu.Unlock()
+
+	switch {
+	case text == previous:
+	case text != "":
+		log.Printf("[WARN] Server %s passed health check with a warning: %s", server.URL, text)
+	default:
+		log.Printf("[INFO] Server %s health check warning cleared", server.URL)
+	}
+}
+
+func (h *HealthChecker) checkServer(ctx context.Context, server *domain.Server) {
+	start := time.Now()
+	err := h.probeFor(server).Check(ctx, server)
+	if ctx.Err() != nil {
+		return
+	}
+
+	var warning *ProbeWarning
+	if errors.As(err, &warning) {
+		err = nil
+	}
+	if err == nil {
+		h.reportWarning(server, warning)
+	}
+
+	record := domain.HealthCheckRecord{Time: start, Latency: time.Since(start), Passed: err == nil}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if warning != nil {
+		record.Warning = warning.Error()
+	}
+	server.RecordHealthCheck(record)
 
 	wasAlive := server.IsAlive()
+	count, threshold := h.record(server, err == nil)
 
 	if err != nil {
-		h.pool.SetServerStatus(server, false)
+		if !wasAlive {
+			return
+		}
 
-		if wasAlive {
-			log.Printf("[WARN] Server %s is DOWN: %v", server.URL, err)
+		if count < threshold {
+			log.Printf("[WARN] Server %s failed health check (%d/%d), still UP: %v",
+				server.URL, count, threshold, err)
+			return
 		}
+
+		h.pool.SetServerStatus(server, false)
+		log.Printf("[WARN] Server %s is DOWN after %d failed checks: %v", server.URL, count, err)
+		log.Printf("[WARN] Server %s recent checks: %s", server.URL, summarizeHistory(server.HealthHistory()))
+		return
+	}
+
+	if h.outliers != nil {
+		h.outliers.HealthCheckPassed(server)
+	}
+
+	if wasAlive {
+		return
+	}
+
+	if count < threshold {
+		log.Printf("[INFO] Server %s passed health check (%d/%d), still DOWN", server.URL, count, threshold)
 		return
 	}
 
-	conn.Close()
 	h.pool.SetServerStatus(server, true)
+	log.Printf("[INFO] Server %s is UP after %d passed checks", server.URL, count)
+}
 
-	if !wasAlive {
-		log.Printf("[INFO] Server %s is UP", server.URL)
+// summarizeHistory renders checks oldest first as "+" or "-", followed by
+// the number of flips between passing and failing and the probe latencies.
+func summarizeHistory(records []domain.HealthCheckRecord) string {
+	if len(records) == 0 {
+		return "none"
+	}
+
+	var b strings.Builder
+	var total, slowest time.Duration
+
+	for _, r := range records {
+		if r.Passed {
+			b.WriteByte('+')
+		} else {
+			b.WriteByte('-')
+		}
+		total += r.Latency
+		slowest = max(slowest, r.Latency)
+	}
+
+	fmt.Fprintf(&b, " (%d flips, latency avg %v, max %v)", countFlips(records),
+		(total / time.Duration(len(records))).Round(time.Millisecond), slowest.Round(time.Millisecond))
+	return b.String()
+}
+
+// countFlips returns how many times consecutive checks changed between
+// passing and failing.
+func countFlips(records []domain.HealthCheckRecord) int {
+	flips := 0
+	for i := 1; i < len(records); i++ {
+		if records[i].Passed != records[i-1].Passed {
+			flips++
+		}
 	}
+	return flips
 }
 
 func (h *HealthChecker) CheckOnce() {
 	servers := h.pool.GetServers()
 
 	for _, server := range servers {
-		h.checkServer(server)
+		h.checkServer(context.Background(), server)
 	}
 }
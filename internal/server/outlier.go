@@ -0,0 +1,191 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+	"janus/internal/metrics"
+)
+
+const (
+	DefaultConsecutive5xx     = 5
+	DefaultBaseEjectionTime   = 30 * time.Second
+	DefaultMaxEjectionTime    = 300 * time.Second
+	DefaultMaxEjectionPercent = 10
+)
+
+type OutlierConfig struct {
+	Consecutive5xx           int
+	ConsecutiveGatewayErrors int
+	BaseEjectionTime         time.Duration
+	MaxEjectionTime          time.Duration
+	MaxEjectionPercent       float64
+	// UnejectOnHealthCheck ends an ejection as soon as an active health
+	// check passes, instead of waiting out the ejection time.
+	UnejectOnHealthCheck bool
+}
+
+type outlierStats struct {
+	consecutive5xx     int
+	consecutiveGateway int
+	ejections          int
+	lastUntil          time.Time
+}
+
+// OutlierDetector ejects servers that keep failing real traffic. Each repeat
+// ejection doubles the time out of rotation, and every base ejection time a
+// server spends back in rotation takes one doubling away again.
+type OutlierDetector struct {
+	pool      *domain.ServerPool
+	cfg       OutlierConfig
+	mu        sync.Mutex
+	stats     map[*domain.Server]*outlierStats
+	ejections *metrics.Counter
+}
+
+func NewOutlierDetector(pool *domain.ServerPool, cfg OutlierConfig) *OutlierDetector {
+	if cfg.Consecutive5xx <= 0 {
+		cfg.Consecutive5xx = DefaultConsecutive5xx
+	}
+	if cfg.BaseEjectionTime <= 0 {
+		cfg.BaseEjectionTime = DefaultBaseEjectionTime
+	}
+	if cfg.MaxEjectionTime < cfg.BaseEjectionTime {
+		cfg.MaxEjectionTime = max(DefaultMaxEjectionTime, cfg.BaseEjectionTime)
+	}
+	if cfg.MaxEjectionPercent <= 0 {
+		cfg.MaxEjectionPercent = DefaultMaxEjectionPercent
+	}
+
+	return &OutlierDetector{
+		pool:  pool,
+		cfg:   cfg,
+		stats: make(map[*domain.Server]*outlierStats),
+	}
+}
+
+func (d *OutlierDetector) Instrument(registry *metrics.Registry) {
+	d.ejections = registry.Counter("janus_outlier_ejections_total", "Number of servers ejected by outlier detection.")
+	registry.GaugeFunc("janus_outlier_ejected", "Number of servers currently ejected.", func() float64 {
+		return float64(d.pool.EjectedCount())
+	})
+}
+
+func (d *OutlierDetector) Report(server *domain.Server, result balancer.Result) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.statsFor(server)
+
+	if !result.Failed() {
+		stats.consecutive5xx = 0
+		stats.consecutiveGateway = 0
+		return
+	}
+
+	stats.consecutive5xx++
+	if isGatewayError(result) {
+		stats.consecutiveGateway++
+	} else {
+		stats.consecutiveGateway = 0
+	}
+
+	switch {
+	case stats.consecutive5xx >= d.cfg.Consecutive5xx:
+		d.eject(server, stats, "consecutive 5xx")
+	case d.cfg.ConsecutiveGatewayErrors > 0 && stats.consecutiveGateway >= d.cfg.ConsecutiveGatewayErrors:
+		d.eject(server, stats, "consecutive gateway errors")
+	}
+}
+
+func (d *OutlierDetector) eject(server *domain.Server, stats *outlierStats, reason string) {
+	now := time.Now()
+
+	if !stats.lastUntil.IsZero() && now.After(stats.lastUntil) {
+		recovered := int(now.Sub(stats.lastUntil) / d.cfg.BaseEjectionTime)
+		stats.ejections = max(stats.ejections-recovered, 0)
+	}
+
+	duration := d.cfg.MaxEjectionTime
+	if stats.ejections < 32 {
+		duration = min(d.cfg.BaseEjectionTime<<stats.ejections, d.cfg.MaxEjectionTime)
+	}
+	until := now.Add(duration)
+
+	if !d.pool.EjectServer(server, until, d.cfg.MaxEjectionPercent) {
+		return
+	}
+
+	stats.ejections++
+	stats.lastUntil = until
+	stats.consecutive5xx = 0
+	stats.consecutiveGateway = 0
+
+	if d.ejections != nil {
+		d.ejections.Inc()
+	}
+
+	log.Printf("[WARN] Server %s ejected for %v after %s (ejection #%d)", server.URL, duration, reason, stats.ejections)
+
+	time.AfterFunc(duration, func() {
+		d.release(server)
+	})
+}
+
+func (d *OutlierDetector) release(server *domain.Server) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	until := server.EjectedUntil()
+	if until.IsZero() || time.Now().Before(until) {
+		return
+	}
+
+	if d.pool.UnejectServer(server) {
+		log.Printf("[INFO] Server %s returned from ejection", server.URL)
+	}
+}
+
+// HealthCheckPassed is called by the health checker after a passing active
+// check. It ends an ejection early only when configured to, and then counts
+// the ejection as over from now on.
+func (d *OutlierDetector) HealthCheckPassed(server *domain.Server) {
+	if !d.cfg.UnejectOnHealthCheck || !server.IsEjected() {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.pool.UnejectServer(server) {
+		return
+	}
+
+	stats := d.statsFor(server)
+	stats.lastUntil = time.Now()
+	stats.consecutive5xx = 0
+	stats.consecutiveGateway = 0
+
+	log.Printf("[INFO] Server %s passed an active health check, ending its ejection", server.URL)
+}
+
+func (d *OutlierDetector) statsFor(server *domain.Server) *outlierStats {
+	stats, ok := d.stats[server]
+	if !ok {
+		stats = &outlierStats{}
+		d.stats[server] = stats
+	}
+	return stats
+}
+
+func isGatewayError(result balancer.Result) bool {
+	switch result.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return result.Err != nil
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"janus/internal/domain"
+)
+
+const (
+	DefaultProbeTimeout = 2 * time.Second
+	maxProbeBody        = 64 << 10
+)
+
+type Probe interface {
+	Check(ctx context.Context, server *domain.Server) error
+}
+
+type TCPProbe struct {
+	Timeout time.Duration
+}
+
+func NewTCPProbe(timeout time.Duration) *TCPProbe {
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	return &TCPProbe{Timeout: timeout}
+}
+
+func (p *TCPProbe) Check(ctx context.Context, server *domain.Server) error {
+	dialer := net.Dialer{Timeout: p.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", dialAddress(server))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+// ParseStatusRanges accepts single codes ("204") and inclusive ranges
+// ("200-299").
+func ParseStatusRanges(specs []string) ([]StatusRange, error) {
+	ranges := make([]StatusRange, 0, len(specs))
+
+	for _, spec := range specs {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+		if !isRange {
+			hi = lo
+		}
+
+		min, errMin := strconv.Atoi(strings.TrimSpace(lo))
+		max, errMax := strconv.Atoi(strings.TrimSpace(hi))
+		if errMin != nil || errMax != nil || min < 100 || max > 599 || min > max {
+			return nil, fmt.Errorf("invalid status range %q", spec)
+		}
+
+		ranges = append(ranges, StatusRange{Min: min, Max: max})
+	}
+
+	return ranges, nil
+}
+
+type HTTPProbeConfig struct {
+	Path           string
+	Method         string
+	Headers        map[string]string
+	Host           string
+	ExpectedStatus []string
+	Body           string
+	BodyRegex      string
+	Timeout        time.Duration
+}
+
+type HTTPProbe struct {
+	path      string
+	method    string
+	headers   http.Header
+	host      string
+	statuses  []StatusRange
+	body      string
+	bodyRegex *regexp.Regexp
+	client    *http.Client
+}
+
+// Validate reports settings NewHTTPProbe would reject, without building the
+// probe.
+func (cfg HTTPProbeConfig) Validate() error {
+	if cfg.Path != "" && !strings.HasPrefix(cfg.Path, "/") {
+		return fmt.Errorf("path must start with /, got %q", cfg.Path)
+	}
+
+	if cfg.Method != "" {
+		if _, err := http.NewRequest(cfg.Method, "http://localhost/", nil); err != nil {
+			return fmt.Errorf("invalid method %q", cfg.Method)
+		}
+	}
+
+	if _, err := ParseStatusRanges(cfg.ExpectedStatus); err != nil {
+		return err
+	}
+
+	if cfg.BodyRegex != "" {
+		if cfg.Body != "" {
+			return errors.New("body and body_regex are mutually exclusive")
+		}
+		if _, err := regexp.Compile(cfg.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body_regex: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func NewHTTPProbe(cfg HTTPProbeConfig) (*HTTPProbe, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &HTTPProbe{
+		path:    cfg.Path,
+		method:  cfg.Method,
+		headers: make(http.Header),
+		host:    cfg.Host,
+		body:    cfg.Body,
+	}
+
+	if p.path == "" {
+		p.path = "/"
+	}
+	if p.method == "" {
+		p.method = http.MethodGet
+	}
+
+	for name, value := range cfg.Headers {
+		p.headers.Set(name, value)
+	}
+
+	specs := cfg.ExpectedStatus
+	if len(specs) == 0 {
+		specs = []string{"200-299"}
+	}
+	p.statuses, _ = ParseStatusRanges(specs)
+
+	if cfg.BodyRegex != "" {
+		p.bodyRegex = regexp.MustCompile(cfg.BodyRegex)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+
+	p.client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: probeDialer(timeout), DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return p, nil
+}
+
+func (p *HTTPProbe) Check(ctx context.Context, server *domain.Server) error {
+	ctx = withDialAddress(ctx, server)
+	target := *server.URL
+	target.Path = p.path
+	target.RawPath = ""
+	target.RawQuery = ""
+
+	if path, query, ok := strings.Cut(p.path, "?"); ok {
+		target.Path = path
+		target.RawQuery = query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.method, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header = p.headers.Clone()
+	req.Header.Set("User-Agent", "janus-health-check")
+	if p.host != "" {
+		req.Host = p.host
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !p.statusExpected(resp.StatusCode) {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if p.body == "" && p.bodyRegex == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	if p.bodyRegex != nil && !p.bodyRegex.Match(body) {
+		return fmt.Errorf("body does not match %q", p.bodyRegex)
+	}
+	if p.body != "" && !strings.Contains(string(body), p.body) {
+		return fmt.Errorf("body does not contain %q", p.body)
+	}
+
+	return nil
+}
+
+func (p *HTTPProbe) statusExpected(code int) bool {
+	for _, r := range p.statuses {
+		if r.Contains(code) {
+			return true
+		}
+	}
+	return false
+}
+
+type dialAddressKey struct{}
+
+// withDialAddress makes a probe transport built with probeDialer connect to
+// the server's health check address. The request URL keeps the backend
+// host, so the Host header and TLS server name still name the backend.
+func withDialAddress(ctx context.Context, server *domain.Server) context.Context {
+	return context.WithValue(ctx, dialAddressKey{}, dialAddress(server))
+}
+
+func probeDialer(timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if target, ok := ctx.Value(dialAddressKey{}).(string); ok {
+			addr = target
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+// dialAddress is the host:port a probe connects to: the health check
+// address if one is set, otherwise the server URL host.
+func dialAddress(server *domain.Server) string {
+	if server.HealthCheckAddress != "" {
+		return server.HealthCheckAddress
+	}
+
+	target := server.URL
+	if target.Port() != "" {
+		return target.Host
+	}
+
+	if target.Scheme == "https" {
+		return target.Host + ":443"
+	}
+	return target.Host + ":80"
+}
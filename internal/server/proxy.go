@@ -1,9 +1,12 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"net/http/httputil"
+	"time"
 
 	"janus/internal/balancer"
 	"janus/internal/domain"
@@ -12,6 +15,8 @@ import (
 type ProxyHandler struct {
 	pool     *domain.ServerPool
 	strategy balancer.Strategy
+	sticky   *StickySessions
+	outliers *OutlierDetector
 }
 
 func NewProxyHandler(pool *domain.ServerPool, strategy balancer.Strategy) *ProxyHandler {
@@ -21,8 +26,21 @@ func NewProxyHandler(pool *domain.ServerPool, strategy balancer.Strategy) *Proxy
 	}
 }
 
+func (h *ProxyHandler) SetStickySessions(sticky *StickySessions) {
+	h.sticky = sticky
+}
+
+func (h *ProxyHandler) SetOutlierDetector(outliers *OutlierDetector) {
+	h.outliers = outliers
+}
+
+// maxSelectAttempts bounds how often the strategy is asked again when the
+// chosen server's half-open circuit has no trial slot left.
+const maxSelectAttempts = 3
+
 func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	server := h.strategy.GetNextServer(h.pool)
+	server, pinned := h.selectServer(r)
+
 	if server == nil {
 		log.Printf("[ERROR] No available servers")
 		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
@@ -35,11 +53,61 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[INFO] Forwarding request to %s (connections: %d, strategy: %s)",
 		server.URL, server.GetConnections(), h.strategy.Name())
 
-	proxy := h.createReverseProxy(server)
+	var result balancer.Result
+	proxy := h.createReverseProxy(server, &result)
+
+	if h.sticky != nil && !pinned {
+		cookie := h.sticky.Cookie(server, r.TLS != nil)
+		record := proxy.ModifyResponse
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			resp.Header.Add("Set-Cookie", cookie.String())
+			return record(resp)
+		}
+	}
+
+	start := time.Now()
 	proxy.ServeHTTP(w, r)
+	result.Latency = time.Since(start)
+
+	// A request the client gave up on says nothing about the backend.
+	if errors.Is(result.Err, context.Canceled) {
+		server.ReleaseRequest()
+	} else {
+		server.ObserveLatency(result.Latency)
+		server.RecordResult(!result.Failed())
+
+		if h.outliers != nil {
+			h.outliers.Report(server, result)
+		}
+	}
+
+	if observer, ok := h.strategy.(balancer.Observer); ok {
+		observer.Done(server, r, result)
+	}
 }
 
-func (h *ProxyHandler) createReverseProxy(server *domain.Server) *httputil.ReverseProxy {
+func (h *ProxyHandler) selectServer(r *http.Request) (*domain.Server, bool) {
+	if h.sticky != nil {
+		if server := h.sticky.Lookup(r, h.pool); server != nil && server.AllowRequest() {
+			return server, true
+		}
+	}
+
+	for i := 0; i < maxSelectAttempts; i++ {
+		server := h.strategy.GetNextServer(h.pool, r)
+		if server == nil {
+			return nil, false
+		}
+
+		if server.AllowRequest() {
+			return server, false
+		}
+	}
+
+	return nil, false
+}
+
+func (h *ProxyHandler) createReverseProxy(server *domain.Server, result *balancer.Result) *httputil.ReverseProxy {
 	proxy := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL.Scheme = server.URL.Scheme
@@ -69,9 +137,20 @@ func (h *ProxyHandler) createReverseProxy(server *domain.Server) *httputil.Rever
 				req.Method, req.URL.Path, server.URL, req.URL.Path)
 		},
 
+		ModifyResponse: func(resp *http.Response) error {
+			result.StatusCode = resp.StatusCode
+			return nil
+		},
+
 		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			result.Err = err
+			if errors.Is(err, context.Canceled) {
+				log.Printf("[INFO] Client canceled request to %s", server.URL)
+				return
+			}
+
 			log.Printf("[ERROR] Proxy error for %s: %v", server.URL, err)
-			server.SetAlive(false)
+			result.StatusCode = http.StatusBadGateway
 			http.Error(w, "Bad Gateway", http.StatusBadGateway)
 		},
 	}
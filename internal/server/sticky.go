@@ -0,0 +1,103 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"janus/internal/domain"
+)
+
+type StickySessions struct {
+	cookieName string
+	ttl        time.Duration
+	key        []byte
+	ids        sync.Map
+}
+
+func NewStickySessions(cookieName string, ttl time.Duration, key []byte) *StickySessions {
+	return &StickySessions{
+		cookieName: cookieName,
+		ttl:        ttl,
+		key:        key,
+	}
+}
+
+func (s *StickySessions) Lookup(r *http.Request, pool *domain.ServerPool) *domain.Server {
+	cookie, err := r.Cookie(s.cookieName)
+	if err != nil {
+		return nil
+	}
+
+	id, ok := s.verify(cookie.Value, time.Now())
+	if !ok {
+		return nil
+	}
+
+	for _, server := range pool.GetHealthyServers() {
+		if s.serverID(server) == id {
+			return server
+		}
+	}
+
+	return nil
+}
+
+func (s *StickySessions) Cookie(server *domain.Server, secure bool) *http.Cookie {
+	expires := time.Now().Add(s.ttl)
+	payload := s.serverID(server) + "." + strconv.FormatInt(expires.Unix(), 10)
+
+	return &http.Cookie{
+		Name:     s.cookieName,
+		Value:    payload + "." + s.sign(payload),
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(s.ttl / time.Second),
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (s *StickySessions) verify(value string, now time.Time) (string, bool) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(payload))) {
+		return "", false
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return "", false
+	}
+
+	return parts[0], true
+}
+
+func (s *StickySessions) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *StickySessions) serverID(server *domain.Server) string {
+	if id, ok := s.ids.Load(server); ok {
+		return id.(string)
+	}
+
+	sum := sha256.Sum256([]byte(server.URL.String()))
+	id := hex.EncodeToString(sum[:8])
+	s.ids.Store(server, id)
+
+	return id
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"janus/internal/domain"
+)
+
+// ProbeWarning is returned by probes that passed but found something worth
+// reporting. The health checker treats it as a pass.
+type ProbeWarning struct {
+	Err error
+}
+
+func (w *ProbeWarning) Error() string {
+	return w.Err.Error()
+}
+
+func (w *ProbeWarning) Unwrap() error {
+	return w.Err
+}
+
+type TLSProbeConfig struct {
+	// ServerName is sent as SNI and verified against the certificate;
+	// it defaults to the host that is checked.
+	ServerName string
+	// RootCAs verifies the chain; nil uses the system roots.
+	RootCAs *x509.CertPool
+	// ExpiryWarning and ExpiryFailure report certificates expiring within
+	// the given time as a warning or a failure. Zero disables the check.
+	ExpiryWarning time.Duration
+	ExpiryFailure time.Duration
+	Timeout       time.Duration
+}
+
+// LoadCertPool reads PEM certificates from a file, for use as RootCAs.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// TLSProbe performs a full TLS handshake and verifies the certificate chain.
+type TLSProbe struct {
+	cfg TLSProbeConfig
+}
+
+func NewTLSProbe(cfg TLSProbeConfig) *TLSProbe {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultProbeTimeout
+	}
+	return &TLSProbe{cfg: cfg}
+}
+
+func (p *TLSProbe) Check(ctx context.Context, server *domain.Server) error {
+	serverName := p.cfg.ServerName
+	if serverName == "" {
+		serverName = server.URL.Hostname()
+	}
+
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: p.cfg.Timeout},
+		Config: &tls.Config{
+			ServerName: serverName,
+			RootCAs:    p.cfg.RootCAs,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	conn, err := dialer.DialContext(ctx, "tcp", dialAddress(server))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.VerifiedChains) == 0 {
+		return errors.New("no verified certificate chain")
+	}
+
+	// The chain is only as good as its first certificate to expire.
+	var expiring *x509.Certificate
+	for _, cert := range state.VerifiedChains[0] {
+		if expiring == nil || cert.NotAfter.Before(expiring.NotAfter) {
+			expiring = cert
+		}
+	}
+
+	remaining := time.Until(expiring.NotAfter)
+	err = fmt.Errorf("certificate %q expires in %d days (%s)", expiring.Subject.CommonName,
+		int(remaining.Hours()/24), expiring.NotAfter.UTC().Format(time.DateOnly))
+
+	switch {
+	case p.cfg.ExpiryFailure > 0 && remaining < p.cfg.ExpiryFailure:
+		return err
+	case p.cfg.ExpiryWarning > 0 && remaining < p.cfg.ExpiryWarning:
+		return &ProbeWarning{Err: err}
+	}
+
+	return nil
+}
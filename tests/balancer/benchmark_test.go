@@ -2,6 +2,8 @@ package balancer_test
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"janus/internal/balancer"
@@ -23,7 +25,7 @@ func BenchmarkRoundRobin_GetNextServer_10(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		rr.GetNextServer(pool)
+		rr.GetNextServer(pool, nil)
 	}
 }
 
@@ -33,7 +35,7 @@ func BenchmarkRoundRobin_GetNextServer_100(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		rr.GetNextServer(pool)
+		rr.GetNextServer(pool, nil)
 	}
 }
 
@@ -43,7 +45,7 @@ func BenchmarkWeighted_GetNextServer_10(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		w.GetNextServer(pool)
+		w.GetNextServer(pool, nil)
 	}
 }
 
@@ -53,7 +55,7 @@ func BenchmarkWeighted_GetNextServer_100(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		w.GetNextServer(pool)
+		w.GetNextServer(pool, nil)
 	}
 }
 
@@ -64,7 +66,7 @@ func BenchmarkRoundRobin_Parallel(b *testing.B) {
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			rr.GetNextServer(pool)
+			rr.GetNextServer(pool, nil)
 		}
 	})
 }
@@ -76,7 +78,94 @@ func BenchmarkWeighted_Parallel(b *testing.B) {
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			w.GetNextServer(pool)
+			w.GetNextServer(pool, nil)
 		}
 	})
 }
+
+func createWeightedBenchmarkPool(count int) *domain.ServerPool {
+	pool := domain.NewServerPool()
+	for i := 0; i < count; i++ {
+		server, _ := domain.NewServer(fmt.Sprintf("http://localhost:%d", 8000+i), i*37%100+1)
+		pool.AddServer(server)
+	}
+	return pool
+}
+
+// BenchmarkWeighted_Rebuild_500 resets the strategy before every pick, so
+// each iteration pays for a full schedule build.
+func BenchmarkWeighted_Rebuild_500(b *testing.B) {
+	w := balancer.NewWeighted()
+	pool := createWeightedBenchmarkPool(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Reset()
+		w.GetNextServer(pool, nil)
+	}
+}
+
+// BenchmarkWeighted_HealthChange_500 changes the healthy set before every
+// pick and measures what a request sees while the schedule is rebuilt.
+func BenchmarkWeighted_HealthChange_500(b *testing.B) {
+	w := balancer.NewWeighted()
+	pool := createWeightedBenchmarkPool(500)
+	flapping := pool.GetServers()[0]
+	w.GetNextServer(pool, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool.SetServerStatus(flapping, i%2 == 1)
+		w.GetNextServer(pool, nil)
+	}
+}
+
+func BenchmarkMaglev_GetNextServer_500(b *testing.B) {
+	keys, _ := balancer.NewHashKeyExtractor(balancer.HashKeyPath, "")
+	m, _ := balancer.NewMaglev(keys, balancer.DefaultMaglevTableSize)
+	pool := createBenchmarkPool(500)
+	req := httptest.NewRequest(http.MethodGet, "/object/42", nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.GetNextServer(pool, req)
+	}
+}
+
+func BenchmarkStrategies_Parallel(b *testing.B) {
+	names := []string{
+		"round_robin",
+		"weighted",
+		"least_connections",
+		"weighted_least_connections",
+		"p2c",
+		"least_latency",
+		"random",
+		"weighted_random",
+		"consistent_hash",
+		"bounded_hash",
+		"maglev",
+	}
+
+	pool := createBenchmarkPool(50)
+
+	for _, name := range names {
+		b.Run(name, func(b *testing.B) {
+			strategy, err := balancer.NewStrategy(name)
+			if err != nil {
+				b.Fatalf("failed to create %s: %v", name, err)
+			}
+
+			strategy.GetNextServer(pool, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				for pb.Next() {
+					strategy.GetNextServer(pool, req)
+				}
+			})
+		})
+	}
+}
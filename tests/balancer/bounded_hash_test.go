@@ -0,0 +1,105 @@
+package balancer_test
+
+import (
+	"fmt"
+	"testing"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func newHeaderBoundedHash(t *testing.T, epsilon float64) *balancer.BoundedHash {
+	t.Helper()
+
+	keys, err := balancer.NewHashKeyExtractor(balancer.HashKeyHeader, "X-User-ID")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return balancer.NewBoundedHash(keys, balancer.DefaultVirtualNodes, epsilon)
+}
+
+func TestBoundedHashName(t *testing.T) {
+	b := newHeaderBoundedHash(t, 0.25)
+
+	if b.Name() != "bounded_hash" {
+		t.Errorf("name = %s, want bounded_hash", b.Name())
+	}
+}
+
+func TestBoundedHashEmptyPool(t *testing.T) {
+	b := newHeaderBoundedHash(t, 0.25)
+	pool := domain.NewServerPool()
+
+	if server := b.GetNextServer(pool, requestWithHeader("a")); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestBoundedHashMatchesConsistentHashWhenIdle(t *testing.T) {
+	b := newHeaderBoundedHash(t, 0.25)
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	for i := 0; i < 1000; i++ {
+		req := requestWithHeader(fmt.Sprintf("user-%d", i))
+		if b.GetNextServer(pool, req) != c.GetNextServer(pool, req) {
+			t.Fatalf("key user-%d placed differently from consistent_hash with no load", i)
+		}
+	}
+}
+
+func TestBoundedHashMovesOffFullServer(t *testing.T) {
+	b := newHeaderBoundedHash(t, 0.25)
+	pool := createTestPoolRR(4)
+
+	preferred := b.GetNextServer(pool, requestWithHeader("alice"))
+	for i := 0; i < 10; i++ {
+		preferred.IncrementConnections()
+	}
+
+	selected := b.GetNextServer(pool, requestWithHeader("alice"))
+	if selected == preferred {
+		t.Error("key should move on when its server is over the load cap")
+	}
+
+	if again := b.GetNextServer(pool, requestWithHeader("alice")); again != selected {
+		t.Error("overflow placement should be deterministic for the same load")
+	}
+}
+
+func TestBoundedHashHotKeyRespectsCap(t *testing.T) {
+	epsilon := 0.25
+	b := newHeaderBoundedHash(t, epsilon)
+	pool := createTestPoolRR(4)
+
+	total := 200
+	for i := 0; i < total; i++ {
+		b.GetNextServer(pool, requestWithHeader("hot")).IncrementConnections()
+	}
+
+	limit := int64(float64(total)/4*(1+epsilon)) + 1
+	for _, s := range pool.GetServers() {
+		if s.GetConnections() > limit {
+			t.Errorf("server %s has %d in-flight, cap is %d", s.URL, s.GetConnections(), limit)
+		}
+	}
+}
+
+func TestBoundedHashKeepsAffinityUnderCap(t *testing.T) {
+	b := newHeaderBoundedHash(t, 0.25)
+	pool := createTestPoolRR(4)
+
+	for _, s := range pool.GetServers() {
+		for i := 0; i < 5; i++ {
+			s.IncrementConnections()
+		}
+	}
+
+	first := b.GetNextServer(pool, requestWithHeader("bob"))
+	for i := 0; i < 10; i++ {
+		if b.GetNextServer(pool, requestWithHeader("bob")) != first {
+			t.Fatal("key should stay on its server while load is balanced")
+		}
+	}
+}
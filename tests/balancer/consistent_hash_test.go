@@ -0,0 +1,239 @@
+package balancer_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func newHeaderHash(t *testing.T) *balancer.ConsistentHash {
+	t.Helper()
+
+	keys, err := balancer.NewHashKeyExtractor(balancer.HashKeyHeader, "X-User-ID")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return balancer.NewConsistentHash(keys, balancer.DefaultVirtualNodes)
+}
+
+func requestWithHeader(value string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User-ID", value)
+	return req
+}
+
+func mapKeys(c *balancer.ConsistentHash, pool *domain.ServerPool, count int) map[string]*domain.Server {
+	result := make(map[string]*domain.Server, count)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		result[key] = c.GetNextServer(pool, requestWithHeader(key))
+	}
+	return result
+}
+
+func TestConsistentHashName(t *testing.T) {
+	c := newHeaderHash(t)
+
+	if c.Name() != "consistent_hash" {
+		t.Errorf("name = %s, want consistent_hash", c.Name())
+	}
+}
+
+func TestConsistentHashEmptyPool(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := domain.NewServerPool()
+
+	if server := c.GetNextServer(pool, requestWithHeader("a")); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestConsistentHashSameKeySameServer(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	first := c.GetNextServer(pool, requestWithHeader("alice"))
+	if first == nil {
+		t.Fatal("expected server, got nil")
+	}
+
+	for i := 0; i < 20; i++ {
+		if got := c.GetNextServer(pool, requestWithHeader("alice")); got != first {
+			t.Fatalf("key moved from %s to %s", first.URL, got.URL)
+		}
+	}
+}
+
+func TestConsistentHashKeySources(t *testing.T) {
+	pool := createTestPoolRR(5)
+
+	tests := []struct {
+		name    string
+		source  string
+		keyName string
+		makeReq func(value string) *http.Request
+	}{
+		{"header", balancer.HashKeyHeader, "X-Tenant", func(v string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Tenant", v)
+			return req
+		}},
+		{"cookie", balancer.HashKeyCookie, "session", func(v string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "session", Value: v})
+			return req
+		}},
+		{"path", balancer.HashKeyPath, "", func(v string) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/"+v, nil)
+		}},
+		{"ip", balancer.HashKeyClientIP, "", func(v string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = v + ":1234"
+			return req
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := balancer.NewHashKeyExtractor(tt.source, tt.keyName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := balancer.NewConsistentHash(keys, balancer.DefaultVirtualNodes)
+
+			seen := make(map[*domain.Server]bool)
+			for i := 0; i < 50; i++ {
+				value := fmt.Sprintf("10.0.0.%d", i)
+				first := c.GetNextServer(pool, tt.makeReq(value))
+				second := c.GetNextServer(pool, tt.makeReq(value))
+				if first != second {
+					t.Fatalf("key %s not sticky", value)
+				}
+				seen[first] = true
+			}
+
+			if len(seen) < 2 {
+				t.Error("different keys should spread across servers")
+			}
+		})
+	}
+}
+
+func TestConsistentHashMissingKeyFallsBackToClientIP(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.168.1.10:5555"
+	first := c.GetNextServer(pool, req)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.168.1.10:6666"
+	second := c.GetNextServer(pool, req)
+
+	if first != second {
+		t.Error("requests without the header should hash on client IP")
+	}
+}
+
+func TestConsistentHashEvenSpread(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	counts := make(map[*domain.Server]int)
+	for _, s := range mapKeys(c, pool, 10000) {
+		counts[s]++
+	}
+
+	expected := 10000 / 5
+	for _, s := range pool.GetServers() {
+		if abs(counts[s]-expected) > expected/4 {
+			t.Errorf("server %s got %d keys, expected ~%d", s.URL, counts[s], expected)
+		}
+	}
+}
+
+func TestConsistentHashServerDownMovesOnlyItsKeys(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	before := mapKeys(c, pool, 10000)
+
+	down := pool.GetServers()[2]
+	pool.SetServerStatus(down, false)
+
+	after := mapKeys(c, pool, 10000)
+
+	moved := 0
+	for key, s := range before {
+		if after[key] == s {
+			continue
+		}
+		if s != down {
+			t.Fatalf("key %s moved off healthy server %s", key, s.URL)
+		}
+		moved++
+	}
+
+	if moved == 0 || moved > 10000*3/10 {
+		t.Errorf("moved %d keys, expected about 1/5 of 10000", moved)
+	}
+}
+
+func TestConsistentHashServerAddedMovesAboutOneNth(t *testing.T) {
+	c := newHeaderHash(t)
+	pool := createTestPoolRR(5)
+
+	before := mapKeys(c, pool, 10000)
+
+	added, _ := domain.NewServer("http://localhost:9000", 1)
+	pool.AddServer(added)
+
+	after := mapKeys(c, pool, 10000)
+
+	moved := 0
+	for key, s := range before {
+		if after[key] == s {
+			continue
+		}
+		if after[key] != added {
+			t.Fatalf("key %s moved to existing server %s", key, after[key].URL)
+		}
+		moved++
+	}
+
+	if moved < 10000/12 || moved > 10000/4 {
+		t.Errorf("moved %d keys, expected about 1/6 of 10000", moved)
+	}
+}
+
+func TestHashKeyExtractorValidation(t *testing.T) {
+	tests := []struct {
+		source  string
+		name    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{balancer.HashKeyClientIP, "", false},
+		{balancer.HashKeyPath, "", false},
+		{balancer.HashKeyHeader, "X-User-ID", false},
+		{balancer.HashKeyHeader, "", true},
+		{balancer.HashKeyCookie, "", true},
+		{"query", "", true},
+	}
+
+	for _, tt := range tests {
+		_, err := balancer.NewHashKeyExtractor(tt.source, tt.name)
+		if tt.wantErr && err == nil {
+			t.Errorf("source %q name %q: expected error, got nil", tt.source, tt.name)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("source %q name %q: unexpected error: %v", tt.source, tt.name, err)
+		}
+	}
+}
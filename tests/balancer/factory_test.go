@@ -1,9 +1,15 @@
 package balancer_test
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
 	"testing"
 
 	"janus/internal/balancer"
+	"janus/internal/domain"
 )
 
 func TestNewStrategyRoundRobin(t *testing.T) {
@@ -54,6 +60,55 @@ func TestNewStrategyLeastConnections(t *testing.T) {
 	}
 }
 
+func TestNewStrategyP2C(t *testing.T) {
+	strategy, err := balancer.NewStrategy("p2c")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strategy == nil {
+		t.Fatal("expected strategy, got nil")
+	}
+
+	if strategy.Name() != "p2c" {
+		t.Errorf("name = %s, want p2c", strategy.Name())
+	}
+}
+
+func TestNewStrategyWithOptionsConsistentHash(t *testing.T) {
+	opts := json.RawMessage(`{"hash_key": "header", "hash_key_name": "X-User-ID"}`)
+
+	strategy, err := balancer.NewStrategyWithOptions("consistent_hash", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User-ID", "alice")
+
+	pool := createTestPoolRR(3)
+	first := strategy.GetNextServer(pool, req)
+	for i := 0; i < 5; i++ {
+		if strategy.GetNextServer(pool, req) != first {
+			t.Fatal("consistent_hash should route by the configured header")
+		}
+	}
+}
+
+func TestNewStrategyWithOptionsInvalidHashKey(t *testing.T) {
+	opts := json.RawMessage(`{"hash_key": "cookie"}`)
+
+	strategy, err := balancer.NewStrategyWithOptions("consistent_hash", opts)
+	if err == nil {
+		t.Error("expected error for cookie hash key without name, got nil")
+	}
+
+	if strategy != nil {
+		t.Error("expected nil strategy for error case")
+	}
+}
+
 func TestNewStrategyUnknown(t *testing.T) {
 	strategy, err := balancer.NewStrategy("unknown_strategy")
 
@@ -83,6 +138,14 @@ func TestNewStrategyAllValid(t *testing.T) {
 		"round_robin",
 		"weighted",
 		"least_connections",
+		"p2c",
+		"consistent_hash",
+		"maglev",
+		"bounded_hash",
+		"least_latency",
+		"weighted_least_connections",
+		"random",
+		"weighted_random",
 	}
 
 	for _, name := range validStrategies {
@@ -103,3 +166,121 @@ func TestNewStrategyAllValid(t *testing.T) {
 		})
 	}
 }
+
+type customOptions struct {
+	Pick int `json:"pick"`
+}
+
+func (o *customOptions) Validate() error {
+	if o.Pick < 0 {
+		return errors.New("pick must not be negative")
+	}
+	return nil
+}
+
+type customStrategy struct {
+	pick int
+}
+
+func (c *customStrategy) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+	if len(servers) == 0 {
+		return nil
+	}
+	return servers[c.pick%len(servers)]
+}
+
+func (c *customStrategy) Name() string {
+	return "test_custom"
+}
+
+func init() {
+	balancer.Register("test_custom", customOptions{Pick: 1}, func(opts customOptions) (balancer.Strategy, error) {
+		return &customStrategy{pick: opts.Pick}, nil
+	})
+}
+
+func TestRegisterCustomStrategy(t *testing.T) {
+	if !balancer.IsRegistered("test_custom") {
+		t.Fatal("custom strategy should be registered")
+	}
+
+	pool := createTestPoolRR(3)
+
+	strategy, err := balancer.NewStrategy("test_custom")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strategy.GetNextServer(pool, nil); got != pool.GetServers()[1] {
+		t.Errorf("default options not applied, got %s", got.URL)
+	}
+
+	strategy, err = balancer.NewStrategyWithOptions("test_custom", json.RawMessage(`{"pick": 2}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strategy.GetNextServer(pool, nil); got != pool.GetServers()[2] {
+		t.Errorf("decoded options not applied, got %s", got.URL)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+
+	balancer.Register("round_robin", balancer.NoOptions{}, func(balancer.NoOptions) (balancer.Strategy, error) {
+		return balancer.NewRoundRobin(), nil
+	})
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		options  string
+		wantErr  bool
+	}{
+		{"no options", "round_robin", ``, false},
+		{"null options", "round_robin", `null`, false},
+		{"typed validation", "test_custom", `{"pick": -1}`, true},
+		{"unknown field", "test_custom", `{"other": 1}`, true},
+		{"wrong type", "test_custom", `{"pick": "one"}`, true},
+		{"unknown strategy", "nope", ``, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := balancer.ValidateOptions(tt.strategy, json.RawMessage(tt.options))
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNamesSortedAndComplete(t *testing.T) {
+	names := balancer.Names()
+
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("names should be sorted: %v", names)
+	}
+
+	for _, want := range []string{"round_robin", "weighted", "least_connections", "p2c", "maglev"} {
+		found := false
+		for _, name := range names {
+			if name == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("built-in strategy %s missing from %v", want, names)
+		}
+	}
+}
@@ -19,7 +19,7 @@ func TestLeastConnectionsEmptyPool(t *testing.T) {
 	lc := balancer.NewLeastConnections()
 	pool := domain.NewServerPool()
 
-	server := lc.GetNextServer(pool)
+	server := lc.GetNextServer(pool, nil)
 	if server != nil {
 		t.Error("expected nil for empty pool")
 	}
@@ -33,7 +33,7 @@ func TestLeastConnectionsSingleServer(t *testing.T) {
 	pool.AddServer(server)
 
 	for i := 0; i < 5; i++ {
-		selected := lc.GetNextServer(pool)
+		selected := lc.GetNextServer(pool, nil)
 		if selected == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -60,7 +60,7 @@ func TestLeastConnectionsSelection(t *testing.T) {
 
 	server2.IncrementConnections()
 
-	selected := lc.GetNextServer(pool)
+	selected := lc.GetNextServer(pool, nil)
 	if selected == nil {
 		t.Fatal("expected server, got nil")
 	}
@@ -81,10 +81,10 @@ func TestLeastConnectionsMultipleSelections(t *testing.T) {
 	pool.AddServer(server1)
 	pool.AddServer(server2)
 
-	selected1 := lc.GetNextServer(pool)
+	selected1 := lc.GetNextServer(pool, nil)
 	selected1.IncrementConnections()
 
-	selected2 := lc.GetNextServer(pool)
+	selected2 := lc.GetNextServer(pool, nil)
 
 	if selected2.URL.String() == selected1.URL.String() {
 		t.Error("second selection should choose different server with fewer connections")
@@ -107,7 +107,7 @@ func TestLeastConnectionsSkipsUnhealthy(t *testing.T) {
 		server2.IncrementConnections()
 	}
 
-	selected := lc.GetNextServer(pool)
+	selected := lc.GetNextServer(pool, nil)
 	if selected == nil {
 		t.Fatal("expected server, got nil")
 	}
@@ -125,7 +125,7 @@ func TestLeastConnectionsAllUnhealthy(t *testing.T) {
 	pool.SetServerStatus(server, false)
 	pool.AddServer(server)
 
-	result := lc.GetNextServer(pool)
+	result := lc.GetNextServer(pool, nil)
 	if result != nil {
 		t.Error("expected nil when all servers are unhealthy")
 	}
@@ -149,7 +149,7 @@ func TestLeastConnectionsEqualConnections(t *testing.T) {
 		}
 	}
 
-	selected := lc.GetNextServer(pool)
+	selected := lc.GetNextServer(pool, nil)
 	if selected == nil {
 		t.Fatal("expected server, got nil")
 	}
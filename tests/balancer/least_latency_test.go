@@ -0,0 +1,105 @@
+package balancer_test
+
+import (
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func TestLeastLatencyName(t *testing.T) {
+	l := balancer.NewLeastLatency()
+
+	if l.Name() != "least_latency" {
+		t.Errorf("name = %s, want least_latency", l.Name())
+	}
+}
+
+func TestLeastLatencyEmptyPool(t *testing.T) {
+	l := balancer.NewLeastLatency()
+	pool := domain.NewServerPool()
+
+	if server := l.GetNextServer(pool, nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestLeastLatencyPrefersFasterServer(t *testing.T) {
+	l := balancer.NewLeastLatency()
+	pool := domain.NewServerPool()
+
+	slow, _ := domain.NewServer("http://localhost:8081", 1)
+	fast, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(slow)
+	pool.AddServer(fast)
+
+	slow.ObserveLatency(200 * time.Millisecond)
+	fast.ObserveLatency(10 * time.Millisecond)
+
+	for i := 0; i < 20; i++ {
+		if selected := l.GetNextServer(pool, nil); selected != fast {
+			t.Fatalf("expected faster server, got %s", selected.URL)
+		}
+	}
+}
+
+func TestLeastLatencyAccountsForInFlight(t *testing.T) {
+	l := balancer.NewLeastLatency()
+	pool := domain.NewServerPool()
+
+	slow, _ := domain.NewServer("http://localhost:8081", 1)
+	fast, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(slow)
+	pool.AddServer(fast)
+
+	slow.ObserveLatency(50 * time.Millisecond)
+	fast.ObserveLatency(10 * time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		fast.IncrementConnections()
+	}
+
+	if selected := l.GetNextServer(pool, nil); selected != slow {
+		t.Errorf("busy fast server should lose to idle slow server, got %s", selected.URL)
+	}
+}
+
+func TestLeastLatencyNewServerNotStarved(t *testing.T) {
+	l := balancer.NewLeastLatency()
+	pool := domain.NewServerPool()
+
+	known, _ := domain.NewServer("http://localhost:8081", 1)
+	fresh, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(known)
+	pool.AddServer(fresh)
+
+	known.ObserveLatency(5 * time.Millisecond)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 1000; i++ {
+		counts[l.GetNextServer(pool, nil)]++
+	}
+
+	if counts[fresh] < 300 || counts[known] < 300 {
+		t.Errorf("unsampled server should get a neutral share: known=%d fresh=%d",
+			counts[known], counts[fresh])
+	}
+}
+
+func TestLeastLatencySkipsUnhealthy(t *testing.T) {
+	l := balancer.NewLeastLatency()
+	pool := createTestPoolRR(3)
+
+	servers := pool.GetServers()
+	servers[0].ObserveLatency(time.Millisecond)
+	servers[1].ObserveLatency(100 * time.Millisecond)
+	servers[2].ObserveLatency(100 * time.Millisecond)
+	pool.SetServerStatus(servers[0], false)
+
+	for i := 0; i < 20; i++ {
+		if l.GetNextServer(pool, nil) == servers[0] {
+			t.Fatal("unhealthy server should not receive requests")
+		}
+	}
+}
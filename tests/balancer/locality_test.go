@@ -0,0 +1,261 @@
+package balancer_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+type zonedServer struct {
+	zone   string
+	region string
+}
+
+func newZonedPool(servers ...zonedServer) (*domain.ServerPool, []*domain.Server) {
+	pool := domain.NewServerPool()
+	result := make([]*domain.Server, len(servers))
+
+	for i, zs := range servers {
+		server, _ := domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), 1)
+		server.Zone = zs.zone
+		server.Region = zs.region
+		pool.AddServer(server)
+		result[i] = server
+	}
+
+	return pool, result
+}
+
+func newLocality(t *testing.T, inner string) *balancer.Locality {
+	t.Helper()
+
+	l, err := balancer.NewLocality("a", "eu", 0, func() (balancer.Strategy, error) {
+		return balancer.NewStrategy(inner)
+	})
+	if err != nil {
+		t.Fatalf("failed to create locality: %v", err)
+	}
+
+	return l
+}
+
+func zoneShares(l *balancer.Locality, pool *domain.ServerPool, count int) map[string]int {
+	shares := make(map[string]int)
+	for i := 0; i < count; i++ {
+		shares[l.GetNextServer(pool, nil).Zone]++
+	}
+	return shares
+}
+
+func TestLocalityName(t *testing.T) {
+	l := newLocality(t, "round_robin")
+
+	if l.Name() != "locality/round_robin" {
+		t.Errorf("name = %s, want locality/round_robin", l.Name())
+	}
+}
+
+func TestLocalityValidation(t *testing.T) {
+	newInner := func() (balancer.Strategy, error) { return balancer.NewRoundRobin(), nil }
+
+	if _, err := balancer.NewLocality("", "eu", 0, newInner); err == nil {
+		t.Error("expected error for missing zone")
+	}
+
+	if _, err := balancer.NewLocality("a", "eu", 0.5, newInner); err == nil {
+		t.Error("expected error for overprovisioning factor below 1")
+	}
+}
+
+func TestLocalityEmptyPool(t *testing.T) {
+	l := newLocality(t, "round_robin")
+
+	if server := l.GetNextServer(domain.NewServerPool(), nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestLocalityPrefersLocalZone(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, _ := newZonedPool(
+		zonedServer{"a", "eu"}, zonedServer{"a", "eu"},
+		zonedServer{"b", "eu"}, zonedServer{"c", "us"},
+	)
+
+	shares := zoneShares(l, pool, 1000)
+	if shares["a"] != 1000 {
+		t.Errorf("healthy local zone should take all traffic, got %v", shares)
+	}
+}
+
+func TestLocalityOverflowIsProportional(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, servers := newZonedPool(
+		zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"a", "eu"},
+		zonedServer{"b", "eu"}, zonedServer{"c", "us"},
+	)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	// Half of zone a is healthy: 0.5 * 1.4 = 70% stays local, the rest
+	// overflows to the same region before anything leaves it.
+	shares := zoneShares(l, pool, 10000)
+	if abs(shares["a"]-7000) > 300 || abs(shares["b"]-3000) > 300 || shares["c"] != 0 {
+		t.Errorf("unexpected zone shares %v, want ~7000/3000/0", shares)
+	}
+}
+
+func TestLocalityAbsorbsMinorFailures(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, servers := newZonedPool(
+		zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"a", "eu"},
+		zonedServer{"b", "eu"},
+	)
+
+	pool.SetServerStatus(servers[0], false)
+
+	shares := zoneShares(l, pool, 1000)
+	if shares["a"] != 1000 {
+		t.Errorf("overprovisioned local zone should absorb one failure, got %v", shares)
+	}
+}
+
+func TestLocalityFailsOverWhenLocalZoneDown(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, servers := newZonedPool(zonedServer{"a", "eu"}, zonedServer{"b", "eu"}, zonedServer{"c", "us"})
+
+	pool.SetServerStatus(servers[0], false)
+
+	if shares := zoneShares(l, pool, 1000); shares["b"] != 1000 {
+		t.Errorf("traffic should move to the local region, got %v", shares)
+	}
+
+	pool.SetServerStatus(servers[1], false)
+
+	if shares := zoneShares(l, pool, 1000); shares["c"] != 1000 {
+		t.Errorf("traffic should leave the region only when it is down, got %v", shares)
+	}
+
+	pool.SetServerStatus(servers[0], true)
+
+	if shares := zoneShares(l, pool, 1000); shares["a"] != 1000 {
+		t.Errorf("traffic should return to the local zone, got %v", shares)
+	}
+}
+
+func TestLocalityOverflowsWhenLocalZoneOverloaded(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	l.SetCapacity(10)
+	pool, servers := newZonedPool(zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"b", "eu"})
+
+	for i := 0; i < 40; i++ {
+		servers[0].IncrementConnections()
+		servers[1].IncrementConnections()
+	}
+
+	// Zone a is healthy but holds 80 connections against a capacity of 20,
+	// so only a quarter of new traffic stays local.
+	shares := zoneShares(l, pool, 10000)
+	if abs(shares["a"]-2500) > 300 || abs(shares["b"]-7500) > 300 {
+		t.Errorf("unexpected zone shares %v, want ~2500/7500", shares)
+	}
+
+	for i := 0; i < 40; i++ {
+		servers[0].DecrementConnections()
+		servers[1].DecrementConnections()
+	}
+
+	// Shares are refreshed in the background; one pick starts the refresh.
+	time.Sleep(150 * time.Millisecond)
+	l.GetNextServer(pool, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	if shares := zoneShares(l, pool, 1000); shares["a"] != 1000 {
+		t.Errorf("traffic should return once the local zone has capacity, got %v", shares)
+	}
+}
+
+func TestLocalityIgnoresLoadWithoutCapacity(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, servers := newZonedPool(zonedServer{"a", "eu"}, zonedServer{"b", "eu"})
+
+	for i := 0; i < 1000; i++ {
+		servers[0].IncrementConnections()
+	}
+
+	if shares := zoneShares(l, pool, 1000); shares["a"] != 1000 {
+		t.Errorf("without a capacity only health should matter, got %v", shares)
+	}
+}
+
+func TestLocalityWrapsInnerStrategy(t *testing.T) {
+	l := newLocality(t, "least_connections")
+	pool, servers := newZonedPool(zonedServer{"a", "eu"}, zonedServer{"a", "eu"}, zonedServer{"b", "eu"})
+
+	servers[0].IncrementConnections()
+
+	for i := 0; i < 10; i++ {
+		if selected := l.GetNextServer(pool, nil); selected != servers[1] {
+			t.Fatalf("expected least loaded local server, got %s", selected.URL)
+		}
+	}
+}
+
+func TestLocalityForwardsResults(t *testing.T) {
+	var recorded []*domain.Server
+
+	l, err := balancer.NewLocality("a", "", 0, func() (balancer.Strategy, error) {
+		return &observingStrategy{done: func(s *domain.Server) { recorded = append(recorded, s) }}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to create locality: %v", err)
+	}
+
+	pool, _ := newZonedPool(zonedServer{"a", ""})
+
+	server := l.GetNextServer(pool, nil)
+	l.Done(server, nil, balancer.Result{StatusCode: http.StatusOK})
+
+	if len(recorded) != 1 || recorded[0] != server {
+		t.Error("results should reach the inner strategy")
+	}
+}
+
+type observingStrategy struct {
+	done func(*domain.Server)
+}
+
+func (o *observingStrategy) GetNextServer(pool *domain.ServerPool, _ *http.Request) *domain.Server {
+	servers := pool.GetHealthyServers()
+	if len(servers) == 0 {
+		return nil
+	}
+	return servers[0]
+}
+
+func (o *observingStrategy) Done(server *domain.Server, _ *http.Request, _ balancer.Result) {
+	o.done(server)
+}
+
+func (o *observingStrategy) Name() string {
+	return "observing"
+}
+
+func TestLocalityDuringPanic(t *testing.T) {
+	l := newLocality(t, "round_robin")
+	pool, servers := newZonedPool(zonedServer{"a", "eu"}, zonedServer{"b", "eu"})
+	pool.SetPanicThreshold(0.5)
+
+	for _, s := range servers {
+		pool.SetServerStatus(s, false)
+	}
+
+	if shares := zoneShares(l, pool, 100); shares["a"] != 100 {
+		t.Errorf("panic mode should still prefer the local zone, got %v", shares)
+	}
+}
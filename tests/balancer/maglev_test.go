@@ -0,0 +1,205 @@
+package balancer_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func newPathMaglev(t *testing.T, tableSize int) *balancer.Maglev {
+	t.Helper()
+
+	keys, _ := balancer.NewHashKeyExtractor(balancer.HashKeyPath, "")
+	m, err := balancer.NewMaglev(keys, tableSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return m
+}
+
+func maglevLookups(m *balancer.Maglev, pool *domain.ServerPool, count int) []*domain.Server {
+	result := make([]*domain.Server, count)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i := range result {
+		req.URL.Path = "/object/" + strconv.Itoa(i)
+		result[i] = m.GetNextServer(pool, req)
+	}
+	return result
+}
+
+func TestMaglevName(t *testing.T) {
+	m := newPathMaglev(t, 0)
+
+	if m.Name() != "maglev" {
+		t.Errorf("name = %s, want maglev", m.Name())
+	}
+}
+
+func TestMaglevEmptyPool(t *testing.T) {
+	m := newPathMaglev(t, 0)
+	pool := domain.NewServerPool()
+
+	if server := m.GetNextServer(pool, nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestMaglevTableSizeMustBePrime(t *testing.T) {
+	keys, _ := balancer.NewHashKeyExtractor(balancer.HashKeyPath, "")
+
+	for _, size := range []int{1, 4, 65536, -7} {
+		if _, err := balancer.NewMaglev(keys, size); err == nil {
+			t.Errorf("table size %d: expected error, got nil", size)
+		}
+	}
+
+	if _, err := balancer.NewMaglev(keys, 251); err != nil {
+		t.Errorf("table size 251: unexpected error: %v", err)
+	}
+}
+
+func TestMaglevSameKeySameServer(t *testing.T) {
+	m := newPathMaglev(t, 0)
+	pool := createTestPoolRR(10)
+
+	first := maglevLookups(m, pool, 500)
+	second := maglevLookups(m, pool, 500)
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("key %d moved without a pool change", i)
+		}
+	}
+}
+
+func TestMaglevEvenSpreadLargePool(t *testing.T) {
+	m := newPathMaglev(t, 0)
+	pool := domain.NewServerPool()
+
+	for i := 0; i < 300; i++ {
+		server, _ := domain.NewServer(fmt.Sprintf("http://10.0.%d.%d:8080", i/256, i%256), 1)
+		pool.AddServer(server)
+	}
+
+	counts := make(map[*domain.Server]int)
+	for _, s := range maglevLookups(m, pool, 150000) {
+		counts[s]++
+	}
+
+	if len(counts) != 300 {
+		t.Fatalf("%d servers received keys, want 300", len(counts))
+	}
+
+	for s, count := range counts {
+		if count < 350 || count > 650 {
+			t.Errorf("server %s got %d keys, expected ~500", s.URL, count)
+		}
+	}
+}
+
+func TestMaglevRespectsWeight(t *testing.T) {
+	m := newPathMaglev(t, 0)
+	pool := domain.NewServerPool()
+
+	server1, _ := domain.NewServer("http://localhost:8081", 1)
+	server2, _ := domain.NewServer("http://localhost:8082", 2)
+	server3, _ := domain.NewServer("http://localhost:8083", 1)
+
+	pool.AddServer(server1)
+	pool.AddServer(server2)
+	pool.AddServer(server3)
+
+	counts := make(map[*domain.Server]int)
+	for _, s := range maglevLookups(m, pool, 40000) {
+		counts[s]++
+	}
+
+	if abs(counts[server1]-10000) > 1000 {
+		t.Errorf("server 1 got %d keys, expected ~10000", counts[server1])
+	}
+	if abs(counts[server2]-20000) > 1000 {
+		t.Errorf("server 2 got %d keys, expected ~20000", counts[server2])
+	}
+	if abs(counts[server3]-10000) > 1000 {
+		t.Errorf("server 3 got %d keys, expected ~10000", counts[server3])
+	}
+}
+
+func TestMaglevServerDownMinimalDisruption(t *testing.T) {
+	m := newPathMaglev(t, 0)
+	pool := createTestPoolRR(10)
+
+	before := maglevLookups(m, pool, 20000)
+
+	down := pool.GetServers()[4]
+	pool.SetServerStatus(down, false)
+
+	after := maglevLookups(m, pool, 20000)
+
+	collateral := 0
+	for i := range before {
+		if after[i] == down {
+			t.Fatal("unhealthy server should not receive keys")
+		}
+		if before[i] != down && after[i] != before[i] {
+			collateral++
+		}
+	}
+
+	if collateral > 20000/20 {
+		t.Errorf("%d keys moved between healthy servers, expected under 5%%", collateral)
+	}
+}
+
+func TestMaglevLargePoolWithWarmingServer(t *testing.T) {
+	pool, warming := newWarmingPool(500, 20.3)
+	m := newPathMaglev(t, 0)
+
+	counts := make(map[*domain.Server]int)
+	for _, s := range maglevLookups(m, pool, 200000) {
+		counts[s]++
+	}
+
+	for _, s := range pool.GetServers() {
+		if counts[s] == 0 {
+			t.Fatalf("%s has no table entries", s.URL)
+		}
+	}
+
+	// A full-weight server gets ~400 of the lookups, the warming one ~80.
+	if counts[warming] < 40 || counts[warming] > 160 {
+		t.Errorf("warming server got %d lookups, want ~80", counts[warming])
+	}
+}
+
+func TestMaglevWeightsAboveTableSize(t *testing.T) {
+	m := newPathMaglev(t, 101)
+	pool := domain.NewServerPool()
+
+	weights := []int{97, 89, 83}
+	servers := make([]*domain.Server, len(weights))
+	for i, weight := range weights {
+		servers[i], _ = domain.NewServer("http://localhost:808"+strconv.Itoa(i), weight)
+		pool.AddServer(servers[i])
+	}
+
+	counts := make(map[*domain.Server]int)
+	for _, s := range maglevLookups(m, pool, 30000) {
+		counts[s]++
+	}
+
+	// The weights sum to 269 with only 101 slots; every server still gets
+	// its proportional share instead of the first one taking the table.
+	for i, s := range servers {
+		want := 30000 * weights[i] / 269
+		if abs(counts[s]-want) > 1500 {
+			t.Errorf("server %d got %d keys, expected ~%d", i, counts[s], want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package balancer_test
+
+import (
+	"testing"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func TestP2CName(t *testing.T) {
+	p := balancer.NewP2C()
+
+	if p.Name() != "p2c" {
+		t.Errorf("name = %s, want p2c", p.Name())
+	}
+}
+
+func TestP2CEmptyPool(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := domain.NewServerPool()
+
+	server := p.GetNextServer(pool, nil)
+	if server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestP2CSingleServer(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := createTestPoolRR(1)
+
+	for i := 0; i < 5; i++ {
+		server := p.GetNextServer(pool, nil)
+		if server == nil {
+			t.Fatal("expected server, got nil")
+		}
+		if server.URL.String() != "http://localhost:8081" {
+			t.Errorf("unexpected server URL: %s", server.URL)
+		}
+	}
+}
+
+func TestP2CPrefersFewerConnections(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := domain.NewServerPool()
+
+	server1, _ := domain.NewServer("http://localhost:8081", 1)
+	server2, _ := domain.NewServer("http://localhost:8082", 1)
+
+	pool.AddServer(server1)
+	pool.AddServer(server2)
+
+	for i := 0; i < 3; i++ {
+		server1.IncrementConnections()
+	}
+
+	for i := 0; i < 20; i++ {
+		selected := p.GetNextServer(pool, nil)
+		if selected != server2 {
+			t.Fatalf("expected server with fewer connections, got %s", selected.URL)
+		}
+	}
+}
+
+func TestP2CNeverPicksMostLoaded(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := createTestPoolRR(4)
+
+	busiest := pool.GetServers()[2]
+	for i := 0; i < 10; i++ {
+		busiest.IncrementConnections()
+	}
+
+	for i := 0; i < 200; i++ {
+		if p.GetNextServer(pool, nil) == busiest {
+			t.Fatal("most loaded server should never win a pairwise comparison")
+		}
+	}
+}
+
+func TestP2CFairDistributionOnTies(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := createTestPoolRR(4)
+
+	counts := make(map[string]int)
+	totalRequests := 8000
+
+	for i := 0; i < totalRequests; i++ {
+		selected := p.GetNextServer(pool, nil)
+		if selected == nil {
+			t.Fatal("expected server, got nil")
+		}
+		counts[selected.URL.String()]++
+	}
+
+	expected := totalRequests / 4
+	tolerance := expected / 5
+
+	for _, s := range pool.GetServers() {
+		count := counts[s.URL.String()]
+		if abs(count-expected) > tolerance {
+			t.Errorf("server %s got %d requests, expected ~%d", s.URL, count, expected)
+		}
+	}
+}
+
+func TestP2CBalancesInFlightLoad(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := createTestPoolRR(5)
+
+	for i := 0; i < 500; i++ {
+		p.GetNextServer(pool, nil).IncrementConnections()
+	}
+
+	minConns, maxConns := int64(-1), int64(0)
+	for _, s := range pool.GetServers() {
+		c := s.GetConnections()
+		if minConns == -1 || c < minConns {
+			minConns = c
+		}
+		if c > maxConns {
+			maxConns = c
+		}
+	}
+
+	if maxConns-minConns > 10 {
+		t.Errorf("in-flight spread too wide: min=%d max=%d", minConns, maxConns)
+	}
+}
+
+func TestP2CSkipsUnhealthy(t *testing.T) {
+	p := balancer.NewP2C()
+	pool := createTestPoolRR(3)
+
+	down := pool.GetServers()[1]
+	pool.SetServerStatus(down, false)
+
+	for i := 0; i < 50; i++ {
+		selected := p.GetNextServer(pool, nil)
+		if selected == nil {
+			t.Fatal("expected server, got nil")
+		}
+		if selected == down {
+			t.Error("unhealthy server should not receive requests")
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package balancer_test
+
+import (
+	"testing"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func TestRandomName(t *testing.T) {
+	r := balancer.NewRandom()
+
+	if r.Name() != "random" {
+		t.Errorf("name = %s, want random", r.Name())
+	}
+}
+
+func TestRandomEmptyPool(t *testing.T) {
+	r := balancer.NewRandom()
+	pool := domain.NewServerPool()
+
+	if server := r.GetNextServer(pool, nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestRandomSeededIsReproducible(t *testing.T) {
+	pool := createTestPoolRR(5)
+	a := balancer.NewRandomWithSeed(42)
+	b := balancer.NewRandomWithSeed(42)
+
+	for i := 0; i < 20; i++ {
+		if a.GetNextServer(pool, nil) != b.GetNextServer(pool, nil) {
+			t.Fatalf("pick %d differs between generators with the same seed", i)
+		}
+	}
+}
+
+func TestRandomUniformDistribution(t *testing.T) {
+	r := balancer.NewRandomWithSeed(7)
+	pool := createTestPoolRR(4)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 8000; i++ {
+		counts[r.GetNextServer(pool, nil)]++
+	}
+
+	for _, s := range pool.GetServers() {
+		if abs(counts[s]-2000) > 200 {
+			t.Errorf("server %s got %d requests, expected ~2000", s.URL, counts[s])
+		}
+	}
+}
+
+func TestRandomSkipsUnhealthy(t *testing.T) {
+	r := balancer.NewRandomWithSeed(1)
+	pool := createTestPoolRR(3)
+
+	down := pool.GetServers()[0]
+	pool.SetServerStatus(down, false)
+
+	for i := 0; i < 100; i++ {
+		if r.GetNextServer(pool, nil) == down {
+			t.Fatal("unhealthy server should not receive requests")
+		}
+	}
+}
@@ -31,7 +31,7 @@ func TestRoundRobinEmptyPool(t *testing.T) {
 	rr := balancer.NewRoundRobin()
 	pool := domain.NewServerPool()
 
-	server := rr.GetNextServer(pool)
+	server := rr.GetNextServer(pool, nil)
 	if server != nil {
 		t.Error("expected nil for empty pool")
 	}
@@ -42,7 +42,7 @@ func TestRoundRobinSingleServer(t *testing.T) {
 	pool := createTestPoolRR(1)
 
 	for i := 0; i < 5; i++ {
-		server := rr.GetNextServer(pool)
+		server := rr.GetNextServer(pool, nil)
 		if server == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -59,7 +59,7 @@ func TestRoundRobinDistribution(t *testing.T) {
 	counts := make(map[string]int)
 
 	for i := 0; i < 9; i++ {
-		server := rr.GetNextServer(pool)
+		server := rr.GetNextServer(pool, nil)
 		if server == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -90,7 +90,7 @@ func TestRoundRobinSkipsUnhealthy(t *testing.T) {
 
 	seen := make(map[string]bool)
 	for i := 0; i < 10; i++ {
-		server := rr.GetNextServer(pool)
+		server := rr.GetNextServer(pool, nil)
 		seen[server.URL.String()] = true
 	}
 
@@ -111,7 +111,7 @@ func TestRoundRobinAllUnhealthy(t *testing.T) {
 	pool.SetServerStatus(server, false)
 	pool.AddServer(server)
 
-	result := rr.GetNextServer(pool)
+	result := rr.GetNextServer(pool, nil)
 	if result != nil {
 		t.Error("expected nil when all servers are unhealthy")
 	}
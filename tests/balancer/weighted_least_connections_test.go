@@ -0,0 +1,123 @@
+package balancer_test
+
+import (
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func TestWeightedLeastConnectionsName(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+
+	if w.Name() != "weighted_least_connections" {
+		t.Errorf("name = %s, want weighted_least_connections", w.Name())
+	}
+}
+
+func TestWeightedLeastConnectionsEmptyPool(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := domain.NewServerPool()
+
+	if server := w.GetNextServer(pool, nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestWeightedLeastConnectionsUsesRatio(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := domain.NewServerPool()
+
+	small, _ := domain.NewServer("http://localhost:8081", 1)
+	large, _ := domain.NewServer("http://localhost:8082", 4)
+	pool.AddServer(small)
+	pool.AddServer(large)
+
+	for i := 0; i < 2; i++ {
+		small.IncrementConnections()
+	}
+	for i := 0; i < 6; i++ {
+		large.IncrementConnections()
+	}
+
+	if selected := w.GetNextServer(pool, nil); selected != large {
+		t.Errorf("expected server with lowest connections/weight (6/4 < 2/1), got %s", selected.URL)
+	}
+}
+
+func TestWeightedLeastConnectionsLoadProportionalToWeight(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := domain.NewServerPool()
+
+	server1, _ := domain.NewServer("http://localhost:8081", 1)
+	server2, _ := domain.NewServer("http://localhost:8082", 3)
+	pool.AddServer(server1)
+	pool.AddServer(server2)
+
+	for i := 0; i < 400; i++ {
+		w.GetNextServer(pool, nil).IncrementConnections()
+	}
+
+	if abs(int(server1.GetConnections())-100) > 2 {
+		t.Errorf("server 1 in-flight = %d, expected ~100", server1.GetConnections())
+	}
+	if abs(int(server2.GetConnections())-300) > 2 {
+		t.Errorf("server 2 in-flight = %d, expected ~300", server2.GetConnections())
+	}
+}
+
+func TestWeightedLeastConnectionsRandomTieBreak(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := createTestPoolRR(3)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 3000; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	for _, s := range pool.GetServers() {
+		if abs(counts[s]-1000) > 200 {
+			t.Errorf("server %s got %d picks on ties, expected ~1000", s.URL, counts[s])
+		}
+	}
+}
+
+func TestWeightedLeastConnectionsSkipsUnhealthy(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := createTestPoolRR(2)
+
+	servers := pool.GetServers()
+	pool.SetServerStatus(servers[0], false)
+	for i := 0; i < 10; i++ {
+		servers[1].IncrementConnections()
+	}
+
+	if selected := w.GetNextServer(pool, nil); selected != servers[1] {
+		t.Errorf("expected healthy server, got %v", selected)
+	}
+}
+
+func TestWeightedLeastConnectionsHonorsSlowStart(t *testing.T) {
+	w := balancer.NewWeightedLeastConnections()
+	pool := domain.NewServerPool()
+
+	steady, _ := domain.NewServer("http://localhost:8081", 10)
+	warming, _ := domain.NewServer("http://localhost:8082", 10)
+	warming.SetSlowStart(time.Hour, 1, 10)
+	pool.AddServer(steady)
+	pool.AddServer(warming)
+
+	pool.SetServerStatus(warming, false)
+	pool.SetServerStatus(warming, true)
+
+	for i := 0; i < 5; i++ {
+		steady.IncrementConnections()
+	}
+	warming.IncrementConnections()
+
+	// 5/10 beats 1/1 while the warming server only counts for a tenth.
+	if selected := w.GetNextServer(pool, nil); selected != steady {
+		t.Errorf("expected steady server while the other warms up, got %s", selected.URL)
+	}
+}
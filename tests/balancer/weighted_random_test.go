@@ -0,0 +1,118 @@
+package balancer_test
+
+import (
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+)
+
+func TestWeightedRandomName(t *testing.T) {
+	w := balancer.NewWeightedRandom()
+
+	if w.Name() != "weighted_random" {
+		t.Errorf("name = %s, want weighted_random", w.Name())
+	}
+}
+
+func TestWeightedRandomEmptyPool(t *testing.T) {
+	w := balancer.NewWeightedRandom()
+	pool := domain.NewServerPool()
+
+	if server := w.GetNextServer(pool, nil); server != nil {
+		t.Error("expected nil for empty pool")
+	}
+}
+
+func TestWeightedRandomDistribution(t *testing.T) {
+	w := balancer.NewWeightedRandomWithSeed(99)
+	pool := domain.NewServerPool()
+
+	server1, _ := domain.NewServer("http://localhost:8081", 5)
+	server2, _ := domain.NewServer("http://localhost:8082", 3)
+	server3, _ := domain.NewServer("http://localhost:8083", 2)
+
+	pool.AddServer(server1)
+	pool.AddServer(server2)
+	pool.AddServer(server3)
+
+	counts := make(map[*domain.Server]int)
+	total := 10000
+	for i := 0; i < total; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	expected := map[*domain.Server]int{server1: 5000, server2: 3000, server3: 2000}
+	for s, want := range expected {
+		if abs(counts[s]-want) > total/25 {
+			t.Errorf("server %s got %d requests, expected ~%d", s.URL, counts[s], want)
+		}
+	}
+}
+
+func TestWeightedRandomSeededIsReproducible(t *testing.T) {
+	pool := createBenchmarkPool(10)
+	a := balancer.NewWeightedRandomWithSeed(3)
+	b := balancer.NewWeightedRandomWithSeed(3)
+
+	for i := 0; i < 20; i++ {
+		if a.GetNextServer(pool, nil) != b.GetNextServer(pool, nil) {
+			t.Fatalf("pick %d differs between generators with the same seed", i)
+		}
+	}
+}
+
+func TestWeightedRandomFollowsHealthChanges(t *testing.T) {
+	w := balancer.NewWeightedRandomWithSeed(5)
+	pool := domain.NewServerPool()
+
+	heavy, _ := domain.NewServer("http://localhost:8081", 10)
+	light, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(heavy)
+	pool.AddServer(light)
+
+	w.GetNextServer(pool, nil)
+	pool.SetServerStatus(heavy, false)
+
+	for i := 0; i < 50; i++ {
+		if w.GetNextServer(pool, nil) != light {
+			t.Fatal("unhealthy server should not receive requests")
+		}
+	}
+
+	pool.SetServerStatus(heavy, true)
+
+	seen := false
+	for i := 0; i < 50; i++ {
+		if w.GetNextServer(pool, nil) == heavy {
+			seen = true
+		}
+	}
+	if !seen {
+		t.Error("recovered server should receive requests again")
+	}
+}
+
+func TestWeightedRandomHonorsSlowStart(t *testing.T) {
+	w := balancer.NewWeightedRandomWithSeed(5)
+	pool := domain.NewServerPool()
+
+	steady, _ := domain.NewServer("http://localhost:8081", 1)
+	warming, _ := domain.NewServer("http://localhost:8082", 1)
+	warming.SetSlowStart(time.Hour, 1, 10)
+	pool.AddServer(steady)
+	pool.AddServer(warming)
+
+	pool.SetServerStatus(warming, false)
+	pool.SetServerStatus(warming, true)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 11000; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	if abs(counts[warming]-1000) > 200 {
+		t.Errorf("warming server got %d requests, expected ~1000", counts[warming])
+	}
+}
@@ -1,8 +1,10 @@
 package balancer_test
 
 import (
-	"reflect"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"janus/internal/balancer"
 	"janus/internal/domain"
@@ -20,7 +22,7 @@ func TestWeightedEmptyPool(t *testing.T) {
 	w := balancer.NewWeighted()
 	pool := domain.NewServerPool()
 
-	server := w.GetNextServer(pool)
+	server := w.GetNextServer(pool, nil)
 	if server != nil {
 		t.Error("expected nil for empty pool")
 	}
@@ -34,7 +36,7 @@ func TestWeightedSingleServer(t *testing.T) {
 	pool.AddServer(server)
 
 	for i := 0; i < 5; i++ {
-		selected := w.GetNextServer(pool)
+		selected := w.GetNextServer(pool, nil)
 		if selected == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -60,7 +62,7 @@ func TestWeightedDistribution(t *testing.T) {
 	totalRequests := 400
 
 	for i := 0; i < totalRequests; i++ {
-		selected := w.GetNextServer(pool)
+		selected := w.GetNextServer(pool, nil)
 		if selected == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -105,7 +107,7 @@ func TestWeightedSkipsUnhealthy(t *testing.T) {
 	pool.SetServerStatus(server2, false)
 
 	for i := 0; i < 20; i++ {
-		selected := w.GetNextServer(pool)
+		selected := w.GetNextServer(pool, nil)
 		if selected == nil {
 			t.Fatal("expected server, got nil")
 		}
@@ -123,7 +125,7 @@ func TestWeightedAllUnhealthy(t *testing.T) {
 	pool.SetServerStatus(server, false)
 	pool.AddServer(server)
 
-	result := w.GetNextServer(pool)
+	result := w.GetNextServer(pool, nil)
 	if result != nil {
 		t.Error("expected nil when all servers are unhealthy")
 	}
@@ -137,12 +139,12 @@ func TestWeightedReset(t *testing.T) {
 	pool.AddServer(server)
 
 	for i := 0; i < 5; i++ {
-		w.GetNextServer(pool)
+		w.GetNextServer(pool, nil)
 	}
 
 	w.Reset()
 
-	selected := w.GetNextServer(pool)
+	selected := w.GetNextServer(pool, nil)
 	if selected == nil {
 		t.Error("expected server after reset, got nil")
 	}
@@ -155,6 +157,27 @@ func abs(x int) int {
 	return x
 }
 
+// awaitSchedule lets the background rebuild after a pool change finish.
+func awaitSchedule(w *balancer.Weighted, pool *domain.ServerPool) {
+	w.GetNextServer(pool, nil)
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestWeightedSkipsDownServersWhileRebuilding(t *testing.T) {
+	w := balancer.NewWeighted()
+	pool := createWeightedBenchmarkPool(500)
+	w.GetNextServer(pool, nil)
+
+	down := pool.GetServers()[42]
+	pool.SetServerStatus(down, false)
+
+	for i := 0; i < 1000; i++ {
+		if w.GetNextServer(pool, nil) == down {
+			t.Fatal("server that went down should not be picked while the schedule is rebuilt")
+		}
+	}
+}
+
 func TestWeightedCleanup(t *testing.T) {
 	w := balancer.NewWeighted()
 	pool := domain.NewServerPool()
@@ -164,23 +187,263 @@ func TestWeightedCleanup(t *testing.T) {
 	pool.AddServer(s1)
 	pool.AddServer(s2)
 
-	w.GetNextServer(pool)
+	w.GetNextServer(pool, nil)
+
+	pool.SetServerStatus(s2, false)
 
-	if getMapSize(w) != 2 {
-		t.Fatalf("expected map size 2, got %d", getMapSize(w))
+	for i := 0; i < 4; i++ {
+		if w.GetNextServer(pool, nil) != s1 {
+			t.Fatal("schedule should drop servers that left the healthy set")
+		}
 	}
 
-	pool.SetServerStatus(s2, false)
+	pool.SetServerStatus(s2, true)
+	awaitSchedule(w, pool)
+
+	seen := make(map[*domain.Server]int)
+	for i := 0; i < 4; i++ {
+		seen[w.GetNextServer(pool, nil)]++
+	}
+	if seen[s1] != 2 || seen[s2] != 2 {
+		t.Errorf("schedule should include recovered server: %d:%d", seen[s1], seen[s2])
+	}
+}
+
+// referenceSmoothWRR is the original map-based smooth weighted round-robin
+// the precomputed schedule has to reproduce.
+type referenceSmoothWRR struct {
+	current map[*domain.Server]int
+}
+
+func (r *referenceSmoothWRR) next(servers []*domain.Server) *domain.Server {
+	total := 0
+	for _, s := range servers {
+		total += s.GetWeight()
+		r.current[s] += s.GetWeight()
+	}
+
+	var selected *domain.Server
+	maxWeight := 0
+	for _, s := range servers {
+		if r.current[s] > maxWeight {
+			maxWeight = r.current[s]
+			selected = s
+		}
+	}
+
+	r.current[selected] -= total
+	return selected
+}
+
+func TestWeightedMatchesSmoothWRRSequence(t *testing.T) {
+	weights := []int{5, 1, 1, 3, 2}
+
+	pool := domain.NewServerPool()
+	for i, weight := range weights {
+		server, _ := domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), weight)
+		pool.AddServer(server)
+	}
+
+	w := balancer.NewWeighted()
+	ref := &referenceSmoothWRR{current: make(map[*domain.Server]int)}
+	servers := pool.GetHealthyServers()
+
+	for i := 0; i < 100; i++ {
+		want := ref.next(servers)
+		if got := w.GetNextServer(pool, nil); got != want {
+			t.Fatalf("pick %d = %s, want %s", i, got.URL, want.URL)
+		}
+	}
+}
+
+func TestWeightedMatchesSmoothWRRCycleForManyServers(t *testing.T) {
+	pool := domain.NewServerPool()
+	total := 0
+	for i := 0; i < 100; i++ {
+		weight := (i*37)%50 + 1
+		server, _ := domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), weight)
+		pool.AddServer(server)
+		total += weight
+	}
+
+	w := balancer.NewWeighted()
+	ref := &referenceSmoothWRR{current: make(map[*domain.Server]int)}
+	servers := pool.GetHealthyServers()
+
+	for i := 0; i < total; i++ {
+		want := ref.next(servers)
+		if got := w.GetNextServer(pool, nil); got != want {
+			t.Fatalf("pick %d = %s, want %s", i, got.URL, want.URL)
+		}
+	}
+}
+
+func TestWeightedResetRestartsSchedule(t *testing.T) {
+	pool := domain.NewServerPool()
+	for i, weight := range []int{3, 2, 1} {
+		server, _ := domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), weight)
+		pool.AddServer(server)
+	}
+
+	fresh := balancer.NewWeighted()
+	expected := make([]*domain.Server, 6)
+	for i := range expected {
+		expected[i] = fresh.GetNextServer(pool, nil)
+	}
+
+	w := balancer.NewWeighted()
+	for i := 0; i < 4; i++ {
+		w.GetNextServer(pool, nil)
+	}
+
+	w.Reset()
+
+	for i, want := range expected {
+		if got := w.GetNextServer(pool, nil); got != want {
+			t.Fatalf("pick %d after reset = %s, want %s", i, got.URL, want.URL)
+		}
+	}
+}
 
-	w.GetNextServer(pool)
+func TestWeightedFollowsWeightChanges(t *testing.T) {
+	w := balancer.NewWeighted()
+	pool := domain.NewServerPool()
 
-	if getMapSize(w) != 1 {
-		t.Errorf("expected map size 1 after cleanup, got %d", getMapSize(w))
+	s1, _ := domain.NewServer("http://localhost:8081", 1)
+	s2, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(s1)
+	pool.AddServer(s2)
+
+	w.GetNextServer(pool, nil)
+	pool.SetServerWeight(s1, 3)
+	awaitSchedule(w, pool)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 400; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	if counts[s1] != 300 || counts[s2] != 100 {
+		t.Errorf("distribution after weight change = %d:%d, want 300:100", counts[s1], counts[s2])
 	}
 }
 
-func getMapSize(w *balancer.Weighted) int {
-	val := reflect.Indirect(reflect.ValueOf(w))
-	field := val.FieldByName("currentWeights")
-	return field.Len()
+func TestWeightedConcurrentExactShare(t *testing.T) {
+	w := balancer.NewWeighted()
+	pool := domain.NewServerPool()
+
+	s1, _ := domain.NewServer("http://localhost:8081", 3)
+	s2, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(s1)
+	pool.AddServer(s2)
+
+	var mu sync.Mutex
+	counts := make(map[*domain.Server]int)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make(map[*domain.Server]int)
+			for i := 0; i < 500; i++ {
+				local[w.GetNextServer(pool, nil)]++
+			}
+			mu.Lock()
+			for s, c := range local {
+				counts[s] += c
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counts[s1] != 3000 || counts[s2] != 1000 {
+		t.Errorf("concurrent distribution = %d:%d, want 3000:1000", counts[s1], counts[s2])
+	}
+}
+
+func TestWeightedHonorsSlowStart(t *testing.T) {
+	w := balancer.NewWeighted()
+	pool := domain.NewServerPool()
+
+	steady, _ := domain.NewServer("http://localhost:8081", 1)
+	warming, _ := domain.NewServer("http://localhost:8082", 1)
+	warming.SetSlowStart(300*time.Millisecond, 1, 10)
+	pool.AddServer(steady)
+	pool.AddServer(warming)
+
+	pool.SetServerStatus(warming, false)
+	pool.SetServerStatus(warming, true)
+
+	counts := make(map[*domain.Server]int)
+	for i := 0; i < 110; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	if counts[warming] > 15 {
+		t.Errorf("warming server got %d of 110 picks, want ~10", counts[warming])
+	}
+
+	time.Sleep(time.Second)
+
+	// The first pick after the ramp starts a background rebuild.
+	w.GetNextServer(pool, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	counts = make(map[*domain.Server]int)
+	for i := 0; i < 100; i++ {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	if counts[steady] != 50 || counts[warming] != 50 {
+		t.Errorf("distribution after slow start = %d:%d, want 50:50", counts[steady], counts[warming])
+	}
+}
+
+// newWarmingPool returns n weight-10 servers; the last one is held in slow
+// start at the given percentage of its weight.
+func newWarmingPool(n int, percent float64) (*domain.ServerPool, *domain.Server) {
+	pool := domain.NewServerPool()
+
+	var warming *domain.Server
+	for i := range n {
+		warming, _ = domain.NewServer("http://backend-"+strconv.Itoa(i)+":8080", 10)
+		pool.AddServer(warming)
+	}
+
+	warming.SetSlowStart(time.Hour, 1, percent)
+	pool.SetServerStatus(warming, false)
+	pool.SetServerStatus(warming, true)
+
+	return pool, warming
+}
+
+func TestWeightedLargePoolWithWarmingServer(t *testing.T) {
+	pool, warming := newWarmingPool(500, 20.3)
+	w := balancer.NewWeighted()
+
+	// Steady servers sit at 10 slow-start steps and the warming one at 2,
+	// so the cycle is 499*5 + 1 picks long.
+	const cycle = 499*5 + 1
+
+	start := time.Now()
+	counts := make(map[*domain.Server]int)
+	for range cycle {
+		counts[w.GetNextServer(pool, nil)]++
+	}
+
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("one cycle took %v, the schedule should stay small while a server warms up", elapsed)
+	}
+
+	for _, s := range pool.GetServers() {
+		want := 5
+		if s == warming {
+			want = 1
+		}
+		if counts[s] != want {
+			t.Fatalf("%s got %d picks in one cycle, want %d", s.URL, counts[s], want)
+		}
+	}
 }
@@ -1,12 +1,19 @@
 package config_test
 
 import (
+	"encoding/pem"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"janus/internal/balancer"
 	"janus/internal/config"
+	"janus/internal/domain"
+	"janus/internal/server"
 )
 
 func createTempConfig(t *testing.T, content string) string {
@@ -91,6 +98,11 @@ func TestLoadConfigDefaults(t *testing.T) {
 	if cfg.Servers[0].Weight != 1 {
 		t.Errorf("default weight = %d, want 1", cfg.Servers[0].Weight)
 	}
+
+	if cfg.LatencyDecayTime != config.DefaultLatencyDecay {
+		t.Errorf("default latency_decay_time = %d, want %d",
+			cfg.LatencyDecayTime, config.DefaultLatencyDecay)
+	}
 }
 
 func TestLoadConfigFileNotFound(t *testing.T) {
@@ -157,7 +169,10 @@ func TestLoadConfigInvalidStrategy(t *testing.T) {
 }
 
 func TestLoadConfigValidStrategies(t *testing.T) {
-	strategies := []string{"round_robin", "weighted", "least_connections"}
+	strategies := []string{
+		"round_robin", "weighted", "least_connections", "p2c", "consistent_hash", "maglev",
+		"bounded_hash", "least_latency", "weighted_least_connections", "random", "weighted_random",
+	}
 
 	for _, strategy := range strategies {
 		t.Run(strategy, func(t *testing.T) {
@@ -180,28 +195,864 @@ func TestLoadConfigValidStrategies(t *testing.T) {
 	}
 }
 
-func TestLoadConfigNoServers(t *testing.T) {
+func TestLoadConfigStrategyOptionsOptional(t *testing.T) {
 	content := `{
-		"backends": []
+		"strategy": "consistent_hash",
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	if _, err := config.LoadConfig(configPath); err != nil {
+		t.Fatalf("strategy options should fall back to defaults: %v", err)
+	}
+}
+
+func TestLoadConfigStrategyOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		options  string
+		wantErr  bool
+	}{
+		{"header with name", "consistent_hash", `{"hash_key": "header", "hash_key_name": "X-User-ID"}`, false},
+		{"cookie with name", "consistent_hash", `{"hash_key": "cookie", "hash_key_name": "session"}`, false},
+		{"path", "consistent_hash", `{"hash_key": "path", "virtual_nodes": 50}`, false},
+		{"header without name", "consistent_hash", `{"hash_key": "header"}`, true},
+		{"cookie without name", "consistent_hash", `{"hash_key": "cookie"}`, true},
+		{"unknown hash key", "consistent_hash", `{"hash_key": "query"}`, true},
+		{"negative virtual nodes", "consistent_hash", `{"virtual_nodes": -1}`, true},
+		{"prime table size", "maglev", `{"table_size": 251}`, false},
+		{"non-prime table size", "maglev", `{"table_size": 1000}`, true},
+		{"custom epsilon", "bounded_hash", `{"hash_key": "path", "epsilon": 0.5}`, false},
+		{"negative epsilon", "bounded_hash", `{"epsilon": -0.1}`, true},
+		{"option of another strategy", "consistent_hash", `{"epsilon": 0.5}`, true},
+		{"options for strategy without options", "round_robin", `{"hash_key": "ip"}`, true},
+		{"empty options", "round_robin", `{}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"strategy": "` + tt.strategy + `",
+				"strategy_options": ` + tt.options + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			_, err := config.LoadConfig(configPath)
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigUnknownStrategyListsValidNames(t *testing.T) {
+	content := `{
+		"strategy": "unknown_strategy",
+		"backends": [{"url": "http://localhost:8081"}]
 	}`
 
 	configPath := createTempConfig(t, content)
 	_, err := config.LoadConfig(configPath)
 
 	if err == nil {
-		t.Error("expected error for empty servers, got nil")
+		t.Fatal("expected error for unknown strategy, got nil")
+	}
+
+	for _, name := range []string{"round_robin", "maglev", "least_latency"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q should list %s", err, name)
+		}
 	}
 }
 
-func TestLoadConfigEmptyServerURL(t *testing.T) {
+func TestLoadConfigInvalidLatencyDecay(t *testing.T) {
 	content := `{
-		"backends": [{"url": "", "weight": 1}]
+		"latency_decay_time": -5,
+		"backends": [{"url": "http://localhost:8081"}]
 	}`
 
 	configPath := createTempConfig(t, content)
 	_, err := config.LoadConfig(configPath)
 
 	if err == nil {
-		t.Error("expected error for empty server URL, got nil")
+		t.Error("expected error for negative latency_decay_time, got nil")
+	}
+}
+
+func TestLoadConfigStickySessionDefaults(t *testing.T) {
+	content := `{
+		"sticky_session": {"signing_key": "0123456789abcdef"},
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.StickySession.CookieName != config.DefaultStickyCookie {
+		t.Errorf("default cookie_name = %s, want %s", cfg.StickySession.CookieName, config.DefaultStickyCookie)
+	}
+
+	if cfg.StickySession.TTL != config.DefaultStickyTTL {
+		t.Errorf("default ttl = %d, want %d", cfg.StickySession.TTL, config.DefaultStickyTTL)
+	}
+}
+
+func TestLoadConfigStickySessionDisabledByDefault(t *testing.T) {
+	content := `{
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.StickySession != nil {
+		t.Error("sticky sessions should be disabled unless configured")
+	}
+}
+
+func TestLoadConfigStickySessionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		sticky  string
+		wantErr bool
+	}{
+		{"valid", `{"cookie_name": "route", "ttl": 60, "signing_key": "0123456789abcdef"}`, false},
+		{"missing key", `{"cookie_name": "route"}`, true},
+		{"short key", `{"signing_key": "short"}`, true},
+		{"invalid cookie name", `{"cookie_name": "bad name;", "signing_key": "0123456789abcdef"}`, true},
+		{"negative ttl", `{"ttl": -1, "signing_key": "0123456789abcdef"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"sticky_session": ` + tt.sticky + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			_, err := config.LoadConfig(configPath)
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPriority(t *testing.T) {
+	content := `{
+		"failover_threshold": 0.5,
+		"backends": [
+			{"url": "http://localhost:8081"},
+			{"url": "http://localhost:8082", "priority": 1}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Servers[0].Priority != 0 {
+		t.Errorf("default priority = %d, want 0", cfg.Servers[0].Priority)
+	}
+
+	if cfg.Servers[1].Priority != 1 {
+		t.Errorf("backup priority = %d, want 1", cfg.Servers[1].Priority)
+	}
+
+	if cfg.FailoverThreshold != 0.5 {
+		t.Errorf("failover_threshold = %v, want 0.5", cfg.FailoverThreshold)
+	}
+}
+
+func TestLoadConfigPriorityValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"negative priority", `{"backends": [{"url": "http://localhost:8081", "priority": -1}]}`},
+		{"negative threshold", `{"failover_threshold": -0.1, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"threshold above one", `{"failover_threshold": 1.5, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"negative panic threshold", `{"panic_threshold": -0.1, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"panic threshold above one", `{"panic_threshold": 1.5, "backends": [{"url": "http://localhost:8081"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := createTempConfig(t, tt.content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigLocality(t *testing.T) {
+	content := `{
+		"locality": {"zone": "eu-west-1a", "region": "eu-west-1"},
+		"backends": [
+			{"url": "http://localhost:8081", "zone": "eu-west-1a", "region": "eu-west-1"},
+			{"url": "http://localhost:8082", "zone": "eu-west-1b", "region": "eu-west-1"}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Locality.OverprovisioningFactor != balancer.DefaultOverprovisioningFactor {
+		t.Errorf("default overprovisioning_factor = %v, want %v",
+			cfg.Locality.OverprovisioningFactor, balancer.DefaultOverprovisioningFactor)
+	}
+
+	if cfg.Servers[1].Zone != "eu-west-1b" || cfg.Servers[1].Region != "eu-west-1" {
+		t.Errorf("backend locality = %s/%s, want eu-west-1/eu-west-1b", cfg.Servers[1].Region, cfg.Servers[1].Zone)
+	}
+}
+
+func TestLoadConfigLocalityValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		locality string
+	}{
+		{"missing zone", `{"region": "eu-west-1"}`},
+		{"factor below one", `{"zone": "eu-west-1a", "overprovisioning_factor": 0.8}`},
+		{"negative capacity", `{"zone": "eu-west-1a", "connections_per_weight": -1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"locality": ` + tt.locality + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigSlowStart(t *testing.T) {
+	content := `{
+		"slow_start": {"window": 30},
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.SlowStart.Aggression != domain.DefaultSlowStartAggression {
+		t.Errorf("default aggression = %v, want %v", cfg.SlowStart.Aggression, domain.DefaultSlowStartAggression)
+	}
+
+	if cfg.SlowStart.MinWeightPercent != domain.DefaultSlowStartMinPercent {
+		t.Errorf("default min_weight_percent = %v, want %v",
+			cfg.SlowStart.MinWeightPercent, domain.DefaultSlowStartMinPercent)
+	}
+}
+
+func TestLoadConfigSlowStartValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		slowStart string
+	}{
+		{"missing window", `{"aggression": 2}`},
+		{"negative aggression", `{"window": 30, "aggression": -1}`},
+		{"min percent above 100", `{"window": 30, "min_weight_percent": 150}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"slow_start": ` + tt.slowStart + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigAdminPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		admin   int
+		wantErr bool
+	}{
+		{"disabled", 0, false},
+		{"valid", 9090, false},
+		{"same as proxy port", 8080, true},
+		{"negative", -1, true},
+		{"too high", 70000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"port": 8080,
+				"admin_port": ` + strconv.Itoa(tt.admin) + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			_, err := config.LoadConfig(configPath)
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigHealthCheckDefaults(t *testing.T) {
+	content := `{
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	check := cfg.Servers[0].HealthCheck
+	if check.Type != config.HealthCheckTCP || time.Duration(check.Timeout)*time.Second != server.DefaultProbeTimeout {
+		t.Errorf("default health check = %s/%ds, want tcp/%v", check.Type, check.Timeout, server.DefaultProbeTimeout)
+	}
+}
+
+func TestLoadConfigHealthCheckOverrides(t *testing.T) {
+	content := `{
+		"health_check": {
+			"type": "http",
+			"path": "/health",
+			"headers": {"X-Probe": "janus", "X-Env": "prod"},
+			"expected_status": ["200-299"]
+		},
+		"backends": [
+			{"url": "http://localhost:8081"},
+			{"url": "http://localhost:8082", "health_check": {"path": "/status", "headers": {"X-Env": "canary"}, "timeout": 5}},
+			{"url": "http://localhost:8083", "health_check": {"type": "tcp"}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := cfg.Servers[0].HealthCheck
+	if first.Type != config.HealthCheckHTTP || first.Path != "/health" {
+		t.Errorf("backend without override = %s %s, want the global http /health", first.Type, first.Path)
+	}
+
+	second := cfg.Servers[1].HealthCheck
+	if second.Type != config.HealthCheckHTTP || second.Path != "/status" || second.Timeout != 5 {
+		t.Errorf("override = %s %s %ds, want http /status 5s", second.Type, second.Path, second.Timeout)
+	}
+	if second.Headers["X-Probe"] != "janus" || second.Headers["X-Env"] != "canary" {
+		t.Errorf("headers should merge by name, got %v", second.Headers)
+	}
+	if cfg.HealthCheck.Headers["X-Env"] != "prod" {
+		t.Error("override must not modify the global health check")
+	}
+
+	if cfg.Servers[2].HealthCheck.Type != config.HealthCheckTCP {
+		t.Errorf("type override = %s, want tcp", cfg.Servers[2].HealthCheck.Type)
+	}
+}
+
+func TestLoadConfigGRPCHealthCheck(t *testing.T) {
+	content := `{
+		"health_check": {"type": "grpc"},
+		"backends": [
+			{"url": "http://localhost:8081"},
+			{"url": "http://localhost:8082", "health_check": {"service": "payments.v1"}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := cfg.Servers[0].HealthCheck
+	if first.Type != config.HealthCheckGRPC || first.Service != "" {
+		t.Errorf("global check = %s %q, want grpc for the whole server", first.Type, first.Service)
+	}
+
+	second := cfg.Servers[1].HealthCheck
+	if second.Type != config.HealthCheckGRPC || second.Service != "payments.v1" {
+		t.Errorf("override = %s %q, want grpc payments.v1", second.Type, second.Service)
+	}
+}
+
+func TestLoadConfigHealthCheckValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown type", `{"health_check": {"type": "udp"}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"negative timeout", `{"health_check": {"timeout": -1}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"invalid status", `{"health_check": {"type": "http", "expected_status": ["2xx"]}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"invalid regex", `{"health_check": {"type": "http", "body_regex": "("}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"invalid backend override", `{"backends": [{"url": "http://localhost:8081", "health_check": {"type": "http", "path": "health"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := createTempConfig(t, tt.content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigHealthThresholds(t *testing.T) {
+	tests := []struct {
+		name          string
+		thresholds    string
+		wantHealthy   int
+		wantUnhealthy int
+		wantErr       bool
+	}{
+		{"defaults", ``, config.DefaultHealthThreshold, config.DefaultHealthThreshold, false},
+		{"custom", `"healthy_threshold": 2, "unhealthy_threshold": 3,`, 2, 3, false},
+		{"negative healthy", `"healthy_threshold": -1,`, 0, 0, true},
+		{"negative unhealthy", `"unhealthy_threshold": -2,`, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{` + tt.thresholds + `
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			cfg, err := config.LoadConfig(configPath)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.HealthyThreshold != tt.wantHealthy || cfg.UnhealthyThreshold != tt.wantUnhealthy {
+				t.Errorf("thresholds = %d/%d, want %d/%d",
+					cfg.HealthyThreshold, cfg.UnhealthyThreshold, tt.wantHealthy, tt.wantUnhealthy)
+			}
+		})
+	}
+}
+
+func TestLoadConfigOutlierDetection(t *testing.T) {
+	content := `{
+		"outlier_detection": {"consecutive_gateway_errors": 3},
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outlier := cfg.OutlierDetection.Config()
+	if outlier.Consecutive5xx != server.DefaultConsecutive5xx || outlier.ConsecutiveGatewayErrors != 3 {
+		t.Errorf("thresholds = %d/%d, want %d/3",
+			outlier.Consecutive5xx, outlier.ConsecutiveGatewayErrors, server.DefaultConsecutive5xx)
+	}
+
+	if outlier.BaseEjectionTime != server.DefaultBaseEjectionTime || outlier.MaxEjectionTime != server.DefaultMaxEjectionTime {
+		t.Errorf("ejection times = %v/%v, want defaults", outlier.BaseEjectionTime, outlier.MaxEjectionTime)
+	}
+
+	if outlier.MaxEjectionPercent != server.DefaultMaxEjectionPercent {
+		t.Errorf("max_ejection_percent = %v, want %v", outlier.MaxEjectionPercent, server.DefaultMaxEjectionPercent)
+	}
+
+	if outlier.UnejectOnHealthCheck {
+		t.Error("uneject_on_health_check should be off by default")
+	}
+}
+
+func TestLoadConfigOutlierDetectionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		outlier string
+	}{
+		{"negative 5xx", `{"consecutive_5xx": -1}`},
+		{"negative gateway errors", `{"consecutive_gateway_errors": -1}`},
+		{"max below base", `{"base_ejection_time": 60, "max_ejection_time": 30}`},
+		{"percent above 100", `{"max_ejection_percent": 150}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"outlier_detection": ` + tt.outlier + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigCircuitBreaker(t *testing.T) {
+	content := `{
+		"circuit_breaker": {"consecutive_failures": 3, "open_timeout": 2},
+		"backends": [{"url": "http://localhost:8081"}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	breaker := cfg.CircuitBreaker.Config()
+	if breaker.ConsecutiveFailures != 3 || breaker.OpenTimeout != 2*time.Second {
+		t.Errorf("breaker = %d failures / %v open, want 3 / 2s", breaker.ConsecutiveFailures, breaker.OpenTimeout)
+	}
+
+	if breaker.ErrorRate != config.DefaultBreakerErrorRate ||
+		breaker.MinRequests != config.DefaultBreakerMinRequests ||
+		breaker.Window != config.DefaultBreakerWindow*time.Second ||
+		breaker.HalfOpenRequests != config.DefaultBreakerHalfOpenRequests {
+		t.Errorf("breaker defaults not applied: %+v", breaker)
+	}
+}
+
+func TestLoadConfigCircuitBreakerValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		breaker string
+	}{
+		{"negative failures", `{"consecutive_failures": -1}`},
+		{"error rate above one", `{"error_rate": 1.5}`},
+		{"negative min requests", `{"min_requests": -1}`},
+		{"negative window", `{"window": -1}`},
+		{"negative open timeout", `{"open_timeout": -1}`},
+		{"negative half-open requests", `{"half_open_requests": -1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{
+				"circuit_breaker": ` + tt.breaker + `,
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigNoServers(t *testing.T) {
+	content := `{
+		"backends": []
+	}`
+
+	configPath := createTempConfig(t, content)
+	_, err := config.LoadConfig(configPath)
+
+	if err == nil {
+		t.Error("expected error for empty servers, got nil")
+	}
+}
+
+func TestLoadConfigEmptyServerURL(t *testing.T) {
+	content := `{
+		"backends": [{"url": "", "weight": 1}]
+	}`
+
+	configPath := createTempConfig(t, content)
+	_, err := config.LoadConfig(configPath)
+
+	if err == nil {
+		t.Error("expected error for empty server URL, got nil")
+	}
+}
+
+func TestLoadConfigHealthCheckScheduling(t *testing.T) {
+	tests := []struct {
+		name            string
+		scheduling      string
+		wantJitter      float64
+		wantConcurrency int
+		wantErr         bool
+	}{
+		{"defaults", ``, server.DefaultHealthCheckJitter, server.DefaultHealthCheckConcurrency, false},
+		{"custom", `"health_check_jitter": 0.25, "health_check_concurrency": 8,`, 0.25, 8, false},
+		{"jitter disabled", `"health_check_jitter": 0,`, 0, server.DefaultHealthCheckConcurrency, false},
+		{"jitter too large", `"health_check_jitter": 1.5,`, 0, 0, true},
+		{"negative jitter", `"health_check_jitter": -0.1,`, 0, 0, true},
+		{"negative concurrency", `"health_check_concurrency": -1,`, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{` + tt.scheduling + `
+				"backends": [{"url": "http://localhost:8081"}]
+			}`
+
+			configPath := createTempConfig(t, content)
+			cfg, err := config.LoadConfig(configPath)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if *cfg.HealthCheckJitter != tt.wantJitter || cfg.HealthCheckConcurrency != tt.wantConcurrency {
+				t.Errorf("scheduling = %v/%d, want %v/%d",
+					*cfg.HealthCheckJitter, cfg.HealthCheckConcurrency, tt.wantJitter, tt.wantConcurrency)
+			}
+		})
+	}
+}
+
+func TestLoadConfigTLSHealthCheck(t *testing.T) {
+	backend := httptest.NewTLSServer(nil)
+	defer backend.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+
+	content := `{
+		"health_check": {"type": "tls", "ca_file": "` + caFile + `", "cert_expiry_warning": 30, "cert_expiry_failure": 7},
+		"backends": [
+			{"url": "https://localhost:8443"},
+			{"url": "https://localhost:9443", "health_check": {"server_name": "api.internal"}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	check := cfg.Servers[1].HealthCheck
+	if check.Type != config.HealthCheckTLS || check.CAFile != caFile || check.ServerName != "api.internal" {
+		t.Errorf("override = %+v, want tls with the global CA and its own server name", check)
+	}
+	if check.CertExpiryWarning != 30 || check.CertExpiryFailure != 7 {
+		t.Errorf("expiry = %d/%d, want 30/7", check.CertExpiryWarning, check.CertExpiryFailure)
+	}
+}
+
+func TestLoadConfigTLSHealthCheckValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		check string
+	}{
+		{"negative expiry", `{"type": "tls", "cert_expiry_failure": -1}`},
+		{"warning not above failure", `{"type": "tls", "cert_expiry_warning": 7, "cert_expiry_failure": 14}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{"health_check": ` + tt.check + `, "backends": [{"url": "https://localhost:8443"}]}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigExecHealthCheck(t *testing.T) {
+	content := `{
+		"health_check": {"type": "exec", "command": ["sh", "-c", "redis-cli -h {host} -p {port} ping"]},
+		"backends": [
+			{"url": "redis://localhost:6379"},
+			{"url": "redis://localhost:6380", "health_check": {"command": ["true"], "timeout": 5}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := cfg.Servers[0].HealthCheck.Command; len(got) != 3 || got[2] != "redis-cli -h {host} -p {port} ping" {
+		t.Errorf("global command = %v", got)
+	}
+
+	second := cfg.Servers[1].HealthCheck
+	if second.Type != config.HealthCheckExec || len(second.Command) != 1 || second.Command[0] != "true" {
+		t.Errorf("override = %s %v, want exec [true]", second.Type, second.Command)
+	}
+}
+
+func TestLoadConfigExecHealthCheckValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		check string
+	}{
+		{"missing command", `{"type": "exec"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `{"health_check": ` + tt.check + `, "backends": [{"url": "http://localhost:8081"}]}`
+
+			configPath := createTempConfig(t, content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadConfigDoesNotResolveProbeResources(t *testing.T) {
+	content := `{
+		"health_check": {"type": "tls", "ca_file": "/nonexistent/ca.pem"},
+		"backends": [
+			{"url": "https://localhost:8443"},
+			{"url": "http://localhost:8081", "health_check": {"type": "exec", "command": ["janus-no-such-command"]}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	if _, err := config.LoadConfig(configPath); err != nil {
+		t.Errorf("CA files and commands are resolved when probes are built, got %v", err)
+	}
+}
+
+func TestLoadConfigHealthCheckTarget(t *testing.T) {
+	content := `{
+		"health_check": {"type": "http", "path": "/healthz", "port": 9090},
+		"backends": [
+			{"url": "http://app-1.internal:8080"},
+			{"url": "http://app-2.internal:8080", "health_check": {"address": "10.0.0.2:9100"}},
+			{"url": "http://app-3.internal:8080", "health_check": {"port": 9200, "path": "/ready"}}
+		]
+	}`
+
+	configPath := createTempConfig(t, content)
+	cfg, err := config.LoadConfig(configPath)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		want string
+		path string
+	}{
+		{"app-1.internal:9090", "/healthz"},
+		{"10.0.0.2:9100", "/healthz"},
+		{"app-3.internal:9200", "/ready"},
+	}
+
+	for i, tt := range tests {
+		check := cfg.Servers[i].HealthCheck
+		srv, _ := domain.NewServer(cfg.Servers[i].URL, 1)
+
+		if got := check.TargetAddress(srv.URL); got != tt.want || check.Path != tt.path {
+			t.Errorf("backend %d checks %s%s, want %s%s", i, got, check.Path, tt.want, tt.path)
+		}
+	}
+
+	plain := config.HealthCheck{}
+	srv, _ := domain.NewServer("http://localhost:8080", 1)
+	if got := plain.TargetAddress(srv.URL); got != "" {
+		t.Errorf("without address or port the URL host should be checked, got %q", got)
+	}
+}
+
+func TestLoadConfigHealthCheckTargetValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"global address", `{"health_check": {"address": "10.0.0.1:9090"}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"address and port", `{"backends": [{"url": "http://localhost:8081", "health_check": {"address": "10.0.0.1:9090", "port": 9090}}]}`},
+		{"address without port", `{"backends": [{"url": "http://localhost:8081", "health_check": {"address": "10.0.0.1"}}]}`},
+		{"address without host", `{"backends": [{"url": "http://localhost:8081", "health_check": {"address": ":9090"}}]}`},
+		{"address with invalid port", `{"backends": [{"url": "http://localhost:8081", "health_check": {"address": "10.0.0.1:70000"}}]}`},
+		{"negative port", `{"health_check": {"port": -1}, "backends": [{"url": "http://localhost:8081"}]}`},
+		{"port too large", `{"backends": [{"url": "http://localhost:8081", "health_check": {"port": 65536}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := createTempConfig(t, tt.content)
+			if _, err := config.LoadConfig(configPath); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
 	}
 }
@@ -0,0 +1,227 @@
+package domain_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+)
+
+func newBreakerServer(t *testing.T, cfg domain.BreakerConfig) (*domain.ServerPool, *domain.Server) {
+	t.Helper()
+
+	if cfg.Window == 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.OpenTimeout == 0 {
+		cfg.OpenTimeout = time.Hour
+	}
+	if cfg.HalfOpenRequests == 0 {
+		cfg.HalfOpenRequests = 1
+	}
+
+	pool := domain.NewServerPool()
+	server, _ := domain.NewServer("http://localhost:8081", 1)
+	server.SetCircuitBreaker(cfg)
+	pool.AddServer(server)
+
+	return pool, server
+}
+
+func recordResults(server *domain.Server, success bool, times int) {
+	for i := 0; i < times; i++ {
+		server.AllowRequest()
+		server.RecordResult(success)
+	}
+}
+
+func waitForState(t *testing.T, server *domain.Server, state domain.BreakerState) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for server.BreakerState() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("breaker state = %s, want %s", server.BreakerState(), state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBreakerDisabledByDefault(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8081", 1)
+
+	recordResults(server, false, 100)
+
+	if server.BreakerState() != domain.BreakerClosed || !server.AllowRequest() {
+		t.Error("servers without a breaker should never trip")
+	}
+}
+
+func TestBreakerTripsOnConsecutiveFailures(t *testing.T) {
+	pool, server := newBreakerServer(t, domain.BreakerConfig{ConsecutiveFailures: 3})
+
+	recordResults(server, false, 2)
+	recordResults(server, true, 1)
+	recordResults(server, false, 2)
+
+	if server.BreakerState() != domain.BreakerClosed {
+		t.Fatal("a success should reset the consecutive failure count")
+	}
+
+	recordResults(server, false, 1)
+
+	if server.BreakerState() != domain.BreakerOpen {
+		t.Fatalf("state = %s, want open", server.BreakerState())
+	}
+	if server.IsAvailable() || len(pool.GetHealthyServers()) != 0 {
+		t.Error("open circuit should take the server out of the healthy set")
+	}
+	if server.AllowRequest() {
+		t.Error("open circuit should reject requests")
+	}
+	if server.BreakerTrips() != 1 {
+		t.Errorf("trips = %d, want 1", server.BreakerTrips())
+	}
+}
+
+func TestBreakerTripsOnErrorRate(t *testing.T) {
+	_, server := newBreakerServer(t, domain.BreakerConfig{ErrorRate: 0.5, MinRequests: 10})
+
+	for i := 0; i < 4; i++ {
+		recordResults(server, true, 1)
+		recordResults(server, false, 1)
+	}
+
+	if server.BreakerState() != domain.BreakerClosed {
+		t.Fatal("breaker should wait for the minimum number of requests")
+	}
+
+	recordResults(server, true, 1)
+	recordResults(server, false, 1)
+
+	if server.BreakerState() != domain.BreakerOpen {
+		t.Errorf("state = %s, want open at a 50%% error rate", server.BreakerState())
+	}
+}
+
+func TestBreakerErrorRateWindowRolls(t *testing.T) {
+	_, server := newBreakerServer(t, domain.BreakerConfig{
+		ErrorRate:   0.5,
+		MinRequests: 4,
+		Window:      100 * time.Millisecond,
+	})
+
+	recordResults(server, false, 1)
+	recordResults(server, true, 1)
+	recordResults(server, false, 1)
+
+	time.Sleep(150 * time.Millisecond)
+
+	recordResults(server, true, 3)
+	recordResults(server, false, 1)
+
+	if server.BreakerState() != domain.BreakerClosed {
+		t.Error("failures outside the window should not count")
+	}
+}
+
+func TestBreakerHalfOpenCloses(t *testing.T) {
+	pool, server := newBreakerServer(t, domain.BreakerConfig{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         30 * time.Millisecond,
+		HalfOpenRequests:    2,
+	})
+
+	recordResults(server, false, 1)
+	waitForState(t, server, domain.BreakerHalfOpen)
+
+	if len(pool.GetHealthyServers()) != 1 {
+		t.Fatal("half-open server should rejoin the healthy set")
+	}
+
+	if !server.AllowRequest() || !server.AllowRequest() {
+		t.Fatal("half-open circuit should allow the trial requests")
+	}
+	if server.AllowRequest() {
+		t.Error("half-open circuit should reject requests beyond the trials")
+	}
+	if len(pool.GetHealthyServers()) != 0 {
+		t.Error("server with all trial slots taken should leave the healthy set")
+	}
+
+	server.RecordResult(true)
+	if len(pool.GetHealthyServers()) != 1 {
+		t.Error("a finished trial should free its slot")
+	}
+
+	server.RecordResult(true)
+	if server.BreakerState() != domain.BreakerClosed {
+		t.Errorf("state = %s, want closed after successful trials", server.BreakerState())
+	}
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	_, server := newBreakerServer(t, domain.BreakerConfig{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         30 * time.Millisecond,
+	})
+
+	recordResults(server, false, 1)
+	waitForState(t, server, domain.BreakerHalfOpen)
+
+	recordResults(server, false, 1)
+
+	if server.BreakerState() != domain.BreakerOpen || server.BreakerTrips() != 2 {
+		t.Errorf("state = %s with %d trips, want open with 2", server.BreakerState(), server.BreakerTrips())
+	}
+}
+
+func TestServerPoolReportsBreakerChanges(t *testing.T) {
+	pool, server := newBreakerServer(t, domain.BreakerConfig{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         20 * time.Millisecond,
+	})
+
+	var mu sync.Mutex
+	var transitions []string
+	pool.OnBreakerChange(func(s *domain.Server, from, to domain.BreakerState) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	recordResults(server, false, 1)
+	waitForState(t, server, domain.BreakerHalfOpen)
+	recordResults(server, true, 1)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	want := []string{"closed->open", "open->half_open", "half_open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Errorf("transition %d = %s, want %s", i, transitions[i], want[i])
+		}
+	}
+}
+
+func TestBreakerReleaseFreesHalfOpenTrial(t *testing.T) {
+	_, server := newBreakerServer(t, domain.BreakerConfig{ConsecutiveFailures: 1, OpenTimeout: 10 * time.Millisecond})
+
+	recordResults(server, false, 1)
+	waitForState(t, server, domain.BreakerHalfOpen)
+
+	if !server.AllowRequest() || server.AllowRequest() {
+		t.Fatal("half-open circuit should allow exactly one trial")
+	}
+
+	server.ReleaseRequest()
+
+	if server.BreakerState() != domain.BreakerHalfOpen || !server.AllowRequest() {
+		t.Error("a released trial should free its slot without changing the state")
+	}
+}
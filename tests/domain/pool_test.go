@@ -1,8 +1,10 @@
 package domain_test
 
 import (
+	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"janus/internal/domain"
 )
@@ -163,3 +165,299 @@ func TestServerPoolConcurrent(t *testing.T) {
 		t.Errorf("pool size = %d, want %d", pool.Size(), iterations)
 	}
 }
+
+func TestServerPoolGeneration(t *testing.T) {
+	pool := domain.NewServerPool()
+	start := pool.Generation()
+
+	server, _ := domain.NewServer("http://localhost:8081", 1)
+	pool.AddServer(server)
+
+	afterAdd := pool.Generation()
+	if afterAdd == start {
+		t.Error("generation should change after AddServer")
+	}
+
+	pool.SetServerStatus(server, true)
+	if pool.Generation() != afterAdd {
+		t.Error("generation should not change when status is unchanged")
+	}
+
+	pool.SetServerStatus(server, false)
+	if pool.Generation() == afterAdd {
+		t.Error("generation should change when the healthy set changes")
+	}
+}
+
+func TestServerPoolSetServerWeight(t *testing.T) {
+	pool := domain.NewServerPool()
+
+	server, _ := domain.NewServer("http://localhost:8081", 1)
+	pool.AddServer(server)
+
+	before := pool.Generation()
+
+	pool.SetServerWeight(server, 1)
+	if pool.Generation() != before {
+		t.Error("generation should not change when weight is unchanged")
+	}
+
+	pool.SetServerWeight(server, 5)
+	if server.GetWeight() != 5 {
+		t.Errorf("weight = %d, want 5", server.GetWeight())
+	}
+	if pool.Generation() == before {
+		t.Error("generation should change when a weight changes")
+	}
+}
+
+func newPriorityPool(t *testing.T, priorities ...int) (*domain.ServerPool, []*domain.Server) {
+	t.Helper()
+
+	pool := domain.NewServerPool()
+	servers := make([]*domain.Server, len(priorities))
+
+	for i, priority := range priorities {
+		server, _ := domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), 1)
+		server.Priority = priority
+		pool.AddServer(server)
+		servers[i] = server
+	}
+
+	return pool, servers
+}
+
+func TestServerPoolRoutesToHighestPriorityTier(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0, 1)
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 2 || healthy[0] != servers[0] || healthy[1] != servers[1] {
+		t.Errorf("healthy servers should be the primary tier only, got %d servers", len(healthy))
+	}
+
+	pool.SetServerStatus(servers[0], false)
+
+	healthy = pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[1] {
+		t.Error("backup should stay idle while a primary is healthy")
+	}
+}
+
+func TestServerPoolFailsOverToBackup(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0, 1)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[2] {
+		t.Fatal("traffic should spill over to the backup when all primaries are down")
+	}
+
+	pool.SetServerStatus(servers[1], true)
+
+	healthy = pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[1] {
+		t.Error("traffic should return to the primary tier once it recovers")
+	}
+}
+
+func TestServerPoolFailoverThreshold(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0, 0, 0, 1)
+	pool.SetFailoverThreshold(0.5)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	if len(pool.GetHealthyServers()) != 2 {
+		t.Fatalf("half of the primaries healthy should not fail over, got %d servers", len(pool.GetHealthyServers()))
+	}
+
+	pool.SetServerStatus(servers[2], false)
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 2 || healthy[0] != servers[3] || healthy[1] != servers[4] {
+		t.Error("below the threshold the remaining primaries and the backup should share traffic")
+	}
+}
+
+func TestServerPoolFailoverSkipsEmptyTiers(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 1, 2)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[2] {
+		t.Error("failover should continue down to the first tier with healthy servers")
+	}
+
+	if pool.HealthyCount() != 1 {
+		t.Errorf("healthy count = %d, want 1", pool.HealthyCount())
+	}
+}
+
+func TestServerPoolSubsetFollowsParent(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0, 0)
+	servers[0].Zone = "a"
+	servers[1].Zone = "a"
+
+	subset := pool.Subset(func(s *domain.Server) bool { return s.Zone == "a" })
+
+	if subset.Size() != 2 || len(subset.GetHealthyServers()) != 2 {
+		t.Fatalf("subset should contain the two matching servers, got %d", subset.Size())
+	}
+
+	before := subset.Generation()
+	pool.SetServerStatus(servers[0], false)
+
+	healthy := subset.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[1] {
+		t.Error("subset should follow health changes of the parent")
+	}
+
+	if subset.Generation() == before {
+		t.Error("subset generation should change with the parent")
+	}
+}
+
+func TestServerPoolRefreshesSubsetsBeforeParent(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0)
+
+	var generations []uint64
+	var healthy []int
+	pool.Subset(func(s *domain.Server) bool {
+		generations = append(generations, pool.Generation())
+		healthy = append(healthy, len(pool.GetHealthyServers()))
+		return true
+	})
+
+	before := pool.Generation()
+	generations, healthy = nil, nil
+	pool.SetServerStatus(servers[0], false)
+
+	if len(generations) == 0 {
+		t.Fatal("subset should be refreshed on a health change")
+	}
+
+	for i := range generations {
+		if generations[i] != before || healthy[i] != 2 {
+			t.Fatal("parent must not publish a new generation before its subsets are refreshed")
+		}
+	}
+
+	if pool.Generation() == before || len(pool.GetHealthyServers()) != 1 {
+		t.Error("parent should publish the change after its subsets")
+	}
+}
+
+func TestServerPoolPanicMode(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0, 0, 0)
+
+	var transitions []bool
+	pool.OnPanicChange(func(panicking bool) {
+		transitions = append(transitions, panicking)
+	})
+	pool.SetPanicThreshold(0.5)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	if pool.InPanic() || len(pool.GetHealthyServers()) != 2 {
+		t.Fatal("half of the servers healthy should not trigger panic mode")
+	}
+
+	pool.SetServerStatus(servers[2], false)
+
+	if !pool.InPanic() {
+		t.Fatal("pool should panic below the threshold")
+	}
+	if len(pool.GetHealthyServers()) != 4 {
+		t.Errorf("panic mode should route to all %d servers, got %d", 4, len(pool.GetHealthyServers()))
+	}
+
+	pool.SetServerStatus(servers[3], false)
+
+	if len(pool.GetHealthyServers()) != 4 {
+		t.Error("panic mode should route to all servers even when none are healthy")
+	}
+
+	pool.SetServerStatus(servers[0], true)
+	pool.SetServerStatus(servers[1], true)
+
+	if pool.InPanic() || len(pool.GetHealthyServers()) != 2 {
+		t.Error("pool should leave panic mode once enough servers recover")
+	}
+
+	if len(transitions) != 2 || !transitions[0] || transitions[1] {
+		t.Errorf("transitions = %v, want [true false]", transitions)
+	}
+}
+
+func TestServerPoolPanicDisabledByDefault(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0)
+
+	pool.SetServerStatus(servers[0], false)
+	pool.SetServerStatus(servers[1], false)
+
+	if pool.InPanic() || len(pool.GetHealthyServers()) != 0 {
+		t.Error("panic mode should be off unless a threshold is set")
+	}
+}
+
+func TestServerPoolPanicIgnoresPriority(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 1)
+	pool.SetPanicThreshold(0.6)
+
+	pool.SetServerStatus(servers[0], false)
+
+	if len(pool.GetHealthyServers()) != 2 {
+		t.Error("panic mode should spread traffic across every tier")
+	}
+}
+
+func TestServerPoolEjectServer(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0)
+	until := time.Now().Add(time.Minute)
+
+	if !pool.EjectServer(servers[0], until, 50) {
+		t.Fatal("ejection should be allowed")
+	}
+
+	if !servers[0].IsEjected() || servers[0].IsAvailable() || !servers[0].IsAlive() {
+		t.Error("ejected server should be unavailable but keep its health state")
+	}
+	if !servers[0].EjectedUntil().Equal(time.Unix(0, until.UnixNano())) {
+		t.Errorf("ejected until = %v, want %v", servers[0].EjectedUntil(), until)
+	}
+
+	if pool.EjectServer(servers[0], until, 100) {
+		t.Error("ejecting an ejected server should be a no-op")
+	}
+	if pool.EjectServer(servers[1], until, 50) {
+		t.Error("second ejection would exceed 50% of the pool")
+	}
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[1] {
+		t.Error("ejected server should leave the healthy set")
+	}
+
+	if !pool.UnejectServer(servers[0]) || servers[0].IsEjected() {
+		t.Error("uneject should return the server")
+	}
+	if len(pool.GetHealthyServers()) != 2 {
+		t.Error("returned server should rejoin the healthy set")
+	}
+}
+
+func TestServerPoolEjectionCountsTowardPanic(t *testing.T) {
+	pool, servers := newPriorityPool(t, 0, 0)
+	pool.SetPanicThreshold(0.6)
+
+	pool.EjectServer(servers[0], time.Now().Add(time.Minute), 100)
+
+	if !pool.InPanic() {
+		t.Error("ejected servers should count as unavailable for panic mode")
+	}
+}
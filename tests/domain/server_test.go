@@ -3,6 +3,7 @@ package domain_test
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"janus/internal/domain"
 )
@@ -59,8 +60,8 @@ func TestNewServer(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			if server.Weight != tt.wantWeight {
-				t.Errorf("weight = %d, want %d", server.Weight, tt.wantWeight)
+			if server.GetWeight() != tt.wantWeight {
+				t.Errorf("weight = %d, want %d", server.GetWeight(), tt.wantWeight)
 			}
 
 			if !server.IsAlive() {
@@ -169,3 +170,147 @@ func TestServerConnectionsConcurrent(t *testing.T) {
 		t.Errorf("final connections = %d, want 0", c)
 	}
 }
+
+func TestServerLatencyEWMA(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 1)
+
+	if _, ok := server.LatencyEWMA(); ok {
+		t.Error("new server should have no latency samples")
+	}
+
+	server.ObserveLatency(100 * time.Millisecond)
+	if got, _ := server.LatencyEWMA(); got < 99*time.Millisecond || got > 100*time.Millisecond {
+		t.Errorf("first sample = %v, want ~100ms", got)
+	}
+
+	server.ObserveLatency(300 * time.Millisecond)
+	if got, _ := server.LatencyEWMA(); got < 299*time.Millisecond {
+		t.Errorf("slower sample should raise the peak EWMA immediately, got %v", got)
+	}
+
+	server.ObserveLatency(time.Millisecond)
+	if got, _ := server.LatencyEWMA(); got < 290*time.Millisecond {
+		t.Errorf("fast sample right after a slow one should barely move the EWMA, got %v", got)
+	}
+}
+
+func TestServerLatencyDecay(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 1)
+	server.SetLatencyDecay(10 * time.Millisecond)
+
+	server.ObserveLatency(100 * time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	got, ok := server.LatencyEWMA()
+	if !ok {
+		t.Fatal("expected latency sample")
+	}
+	if got > 10*time.Millisecond {
+		t.Errorf("idle EWMA should decay toward zero, got %v", got)
+	}
+}
+
+func TestServerSlowStartRamp(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 10)
+	server.SetSlowStart(time.Hour, 1, 10)
+
+	if server.IsWarming() || server.EffectiveWeight() != 10 {
+		t.Fatal("a server that starts healthy should not ramp up")
+	}
+
+	server.SetAlive(false)
+	server.SetAlive(true)
+
+	if !server.IsWarming() {
+		t.Fatal("recovered server should be warming")
+	}
+
+	if got := server.EffectiveWeight(); got < 1 || got > 1.01 {
+		t.Errorf("effective weight right after recovery = %v, want ~1 (10%% floor)", got)
+	}
+}
+
+func TestServerSlowStartCompletes(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 4)
+	server.SetSlowStart(50*time.Millisecond, 1, 10)
+
+	server.SetAlive(false)
+	server.SetAlive(true)
+
+	time.Sleep(25 * time.Millisecond)
+
+	if got := server.EffectiveWeight(); got <= 0.4 || got >= 4 {
+		t.Errorf("effective weight halfway through = %v, want between floor and full weight", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if server.IsWarming() || server.EffectiveWeight() != 4 {
+		t.Errorf("effective weight after window = %v, want 4", server.EffectiveWeight())
+	}
+}
+
+func TestServerSlowStartAggression(t *testing.T) {
+	linear, _ := domain.NewServer("http://localhost:8080", 100)
+	aggressive, _ := domain.NewServer("http://localhost:8081", 100)
+	linear.SetSlowStart(time.Second, 1, 0)
+	aggressive.SetSlowStart(time.Second, 4, 0)
+
+	for _, s := range []*domain.Server{linear, aggressive} {
+		s.SetAlive(false)
+		s.SetAlive(true)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if aggressive.EffectiveWeight() <= linear.EffectiveWeight() {
+		t.Errorf("aggressive ramp %v should lead linear ramp %v",
+			aggressive.EffectiveWeight(), linear.EffectiveWeight())
+	}
+}
+
+func TestServerWithoutSlowStartRecoversAtFullWeight(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 3)
+
+	server.SetAlive(false)
+	server.SetAlive(true)
+
+	if server.IsWarming() || server.EffectiveWeight() != 3 {
+		t.Errorf("effective weight = %v, want 3 without slow start", server.EffectiveWeight())
+	}
+}
+
+func TestServerHealthHistory(t *testing.T) {
+	server, _ := domain.NewServer("http://localhost:8080", 1)
+
+	if history := server.HealthHistory(); len(history) != 0 {
+		t.Fatalf("expected empty history, got %d records", len(history))
+	}
+
+	start := time.Now()
+	total := domain.DefaultHealthHistorySize + 5
+	for i := range total {
+		server.RecordHealthCheck(domain.HealthCheckRecord{
+			Time:    start.Add(time.Duration(i) * time.Second),
+			Latency: time.Duration(i) * time.Millisecond,
+			Passed:  i%2 == 0,
+		})
+	}
+
+	history := server.HealthHistory()
+	if len(history) != domain.DefaultHealthHistorySize {
+		t.Fatalf("history length = %d, want %d", len(history), domain.DefaultHealthHistorySize)
+	}
+
+	for i, record := range history {
+		want := time.Duration(i+5) * time.Millisecond
+		if record.Latency != want {
+			t.Fatalf("record %d latency = %v, want %v (oldest first, oldest dropped)", i, record.Latency, want)
+		}
+	}
+
+	history[0].Passed = !history[0].Passed
+	if server.HealthHistory()[0].Passed == history[0].Passed {
+		t.Error("HealthHistory must return a copy")
+	}
+}
@@ -0,0 +1,123 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"janus/internal/metrics"
+)
+
+func render(t *testing.T, r *metrics.Registry) string {
+	t.Helper()
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("failed to render metrics: %v", err)
+	}
+	return b.String()
+}
+
+func TestCounter(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := r.Counter("requests_total", "Requests served.")
+
+	c.Inc()
+	c.Add(2)
+
+	if c.Value() != 3 {
+		t.Errorf("counter = %d, want 3", c.Value())
+	}
+
+	want := "# HELP requests_total Requests served.\n# TYPE requests_total counter\nrequests_total 3\n"
+	if got := render(t, r); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	r := metrics.NewRegistry()
+	g := r.Gauge("temperature", "Current temperature.")
+
+	g.Set(21.5)
+
+	if !strings.Contains(render(t, r), "temperature 21.5\n") {
+		t.Errorf("gauge missing from output:\n%s", render(t, r))
+	}
+}
+
+func TestGaugeFunc(t *testing.T) {
+	r := metrics.NewRegistry()
+	value := 1.0
+	r.GaugeFunc("dynamic", "Read at scrape time.", func() float64 { return value })
+
+	value = 7
+
+	if !strings.Contains(render(t, r), "dynamic 7\n") {
+		t.Errorf("gauge func should be evaluated at scrape time:\n%s", render(t, r))
+	}
+}
+
+func TestLabels(t *testing.T) {
+	r := metrics.NewRegistry()
+	a := r.Counter("hits_total", "Hits.", "server", "http://a")
+	b := r.Counter("hits_total", "Hits.", "server", `say "hi"`)
+
+	a.Inc()
+	b.Add(2)
+
+	if again := r.Counter("hits_total", "Hits.", "server", "http://a"); again != a {
+		t.Error("registering the same series twice should return the existing counter")
+	}
+
+	out := render(t, r)
+	if strings.Count(out, "# TYPE hits_total counter") != 1 {
+		t.Errorf("family header should be written once:\n%s", out)
+	}
+	if !strings.Contains(out, `hits_total{server="http://a"} 1`) {
+		t.Errorf("missing labelled series:\n%s", out)
+	}
+	if !strings.Contains(out, `hits_total{server="say \"hi\""} 2`) {
+		t.Errorf("label values should be escaped:\n%s", out)
+	}
+}
+
+func TestFamiliesSortedByName(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.Gauge("zeta", "Last.")
+	r.Gauge("alpha", "First.")
+
+	out := render(t, r)
+	if strings.Index(out, "alpha") > strings.Index(out, "zeta") {
+		t.Errorf("families should be sorted by name:\n%s", out)
+	}
+}
+
+func TestKindMismatchPanics(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.Counter("mixed", "Mixed.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic when a name is reused with another type")
+		}
+	}()
+
+	r.Gauge("mixed", "Mixed.")
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.Counter("served_total", "Served.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("content type = %s, want text/plain", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "served_total 1") {
+		t.Errorf("unexpected body:\n%s", rec.Body.String())
+	}
+}
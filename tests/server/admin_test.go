@@ -0,0 +1,144 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+func TestAdminStatusReportsServers(t *testing.T) {
+	pool := domain.NewServerPool()
+
+	primary, _ := domain.NewServer("http://localhost:8081", 4)
+	backup, _ := domain.NewServer("http://localhost:8082", 2)
+	backup.Priority = 1
+	primary.SetSlowStart(time.Hour, 1, 25)
+	pool.AddServer(primary)
+	pool.AddServer(backup)
+
+	pool.SetServerStatus(primary, false)
+	pool.SetServerStatus(primary, true)
+
+	handler := server.NewAdminHandler(pool)
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var status server.PoolStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("invalid status body: %v", err)
+	}
+
+	if len(status.Servers) != 2 {
+		t.Fatalf("servers = %d, want 2", len(status.Servers))
+	}
+
+	got := status.Servers[0]
+	if !got.Warming || got.Weight != 4 || got.EffectiveWeight < 1 || got.EffectiveWeight > 1.01 {
+		t.Errorf("warming server status = %+v, want weight 4 at ~25%%", got)
+	}
+
+	if got.CircuitBreaker != "closed" {
+		t.Errorf("circuit_breaker = %s, want closed", got.CircuitBreaker)
+	}
+
+	if !got.Routable || status.Servers[1].Routable {
+		t.Error("only the primary tier should be routable")
+	}
+}
+
+func TestAdminUnknownPath(t *testing.T) {
+	handler := server.NewAdminHandler(domain.NewServerPool())
+
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminHandleRegistersEndpoint(t *testing.T) {
+	handler := server.NewAdminHandler(domain.NewServerPool())
+	handler.Handle("GET /metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "metrics" {
+		t.Errorf("body = %q, want metrics", rec.Body.String())
+	}
+}
+
+func TestAdminHealthHistory(t *testing.T) {
+	pool := domain.NewServerPool()
+	up, _ := domain.NewServer("http://localhost:8081", 1)
+	flapping, _ := domain.NewServer("http://localhost:8082", 1)
+	pool.AddServer(up)
+	pool.AddServer(flapping)
+
+	start := time.Now()
+	up.RecordHealthCheck(domain.HealthCheckRecord{Time: start, Latency: 2 * time.Millisecond, Passed: true})
+	for i, passed := range []bool{true, false, true, false} {
+		record := domain.HealthCheckRecord{Time: start.Add(time.Duration(i) * time.Second), Passed: passed}
+		if !passed {
+			record.Error = "connection refused"
+		}
+		flapping.RecordHealthCheck(record)
+	}
+
+	handler := server.NewAdminHandler(pool)
+
+	req := httptest.NewRequest(http.MethodGet, "/health/history", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var history []server.ServerHistory
+	if err := json.NewDecoder(rec.Body).Decode(&history); err != nil {
+		t.Fatalf("invalid history body: %v", err)
+	}
+
+	if len(history) != 2 {
+		t.Fatalf("servers = %d, want 2", len(history))
+	}
+	if got := history[0].Checks; len(got) != 1 || !got[0].Passed || got[0].LatencyMs != 2 {
+		t.Errorf("history of healthy server = %+v", got)
+	}
+	if history[1].Flips != 3 || len(history[1].Checks) != 4 || history[1].Checks[3].Error != "connection refused" {
+		t.Errorf("history of flapping server = %+v", history[1])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health/history?server=http://localhost:8082", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var single server.ServerHistory
+	if err := json.NewDecoder(rec.Body).Decode(&single); err != nil {
+		t.Fatalf("invalid history body: %v", err)
+	}
+	if single.URL != "http://localhost:8082" || len(single.Checks) != 4 {
+		t.Errorf("filtered history = %+v", single)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health/history?server=http://localhost:9999", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status for unknown server = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+func newExecProbe(t *testing.T, timeout time.Duration, command ...string) *server.ExecProbe {
+	t.Helper()
+
+	probe, err := server.NewExecProbe(server.ExecProbeConfig{Command: command, Timeout: timeout})
+	if err != nil {
+		t.Fatalf("failed to create probe: %v", err)
+	}
+	return probe
+}
+
+func TestExecProbeExitCode(t *testing.T) {
+	srv, _ := domain.NewServer("http://localhost:6379", 1)
+
+	if err := newExecProbe(t, time.Second, "true").Check(context.Background(), srv); err != nil {
+		t.Errorf("exit code 0 should pass: %v", err)
+	}
+
+	err := newExecProbe(t, time.Second, "sh", "-c", "echo 'PONG expected'; exit 3").Check(context.Background(), srv)
+	if err == nil {
+		t.Fatal("non-zero exit code should fail")
+	}
+	if !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "PONG expected") {
+		t.Errorf("error should carry exit status and output, got %v", err)
+	}
+}
+
+func TestExecProbePassesBackend(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	srv, _ := domain.NewServer("redis://cache.internal:6380", 1)
+
+	probe := newExecProbe(t, time.Second, "sh", "-c",
+		`echo "$1 $2 $JANUS_BACKEND_HOST $JANUS_BACKEND_PORT $JANUS_BACKEND_URL" > `+out, "probe", "{host}", "{port}")
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := os.ReadFile(out)
+	want := "cache.internal 6380 cache.internal 6380 redis://cache.internal:6380\n"
+	if string(got) != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+}
+
+func TestExecProbeDefaultPort(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	srv, _ := domain.NewServer("https://api.internal", 1)
+
+	probe := newExecProbe(t, time.Second, "sh", "-c", `echo "$JANUS_BACKEND_PORT" > `+out)
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, _ := os.ReadFile(out); string(got) != "443\n" {
+		t.Errorf("port = %q, want 443", got)
+	}
+}
+
+func TestExecProbeTimeout(t *testing.T) {
+	srv, _ := domain.NewServer("http://localhost:8080", 1)
+	probe := newExecProbe(t, 100*time.Millisecond, "sleep", "5")
+
+	start := time.Now()
+	err := probe.Check(context.Background(), srv)
+
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("probe took %v, the command should be killed at the timeout", elapsed)
+	}
+}
+
+func TestExecProbeValidation(t *testing.T) {
+	if _, err := server.NewExecProbe(server.ExecProbeConfig{}); err == nil {
+		t.Error("expected error for empty command")
+	}
+
+	if _, err := server.NewExecProbe(server.ExecProbeConfig{Command: []string{"janus-no-such-command"}}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
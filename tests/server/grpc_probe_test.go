@@ -0,0 +1,220 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+const (
+	grpcServing    = 1
+	grpcNotServing = 2
+)
+
+// healthService is a minimal grpc.health.v1.Health implementation that
+// speaks the wire format directly.
+type healthService struct {
+	mu       sync.Mutex
+	statuses map[string]uint64
+	requests []string
+}
+
+func newHealthService(statuses map[string]uint64) *healthService {
+	return &healthService{statuses: statuses}
+}
+
+func (h *healthService) set(service string, status uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.statuses[service] = status
+}
+
+func (h *healthService) lastService() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.requests) == 0 {
+		return "<none>"
+	}
+	return h.requests[len(h.requests)-1]
+}
+
+func (h *healthService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.URL.Path != "/grpc.health.v1.Health/Check" ||
+		r.Header.Get("Content-Type") != "application/grpc" {
+		http.Error(w, "not a grpc health check", http.StatusBadRequest)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	service, ok := decodeHealthRequest(body)
+	if !ok {
+		http.Error(w, "malformed health check request", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	h.requests = append(h.requests, service)
+	status, known := h.statuses[service]
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/grpc")
+
+	if !known {
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "unknown service")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Trailer", "Grpc-Status")
+	msg := binary.AppendUvarint([]byte{0x08}, status)
+	frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(msg)))
+	w.Write(append(frame, msg...))
+	w.Header().Set("Grpc-Status", "0")
+}
+
+// decodeHealthRequest reads the service name from a length-prefixed
+// HealthCheckRequest frame.
+func decodeHealthRequest(body []byte) (string, bool) {
+	if len(body) < 5 || body[0] != 0 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return "", false
+	}
+
+	msg := body[5:]
+	if len(msg) == 0 {
+		return "", true
+	}
+	if msg[0] != 0x0a {
+		return "", false
+	}
+
+	length, n := binary.Uvarint(msg[1:])
+	if n <= 0 || length != uint64(len(msg)-1-n) {
+		return "", false
+	}
+
+	return string(msg[1+n:]), true
+}
+
+func grpcTarget(t *testing.T, handler http.Handler) *domain.Server {
+	t.Helper()
+
+	backend := httptest.NewUnstartedServer(handler)
+	backend.Config.Protocols = new(http.Protocols)
+	backend.Config.Protocols.SetUnencryptedHTTP2(true)
+	backend.Start()
+	t.Cleanup(backend.Close)
+
+	srv, _ := domain.NewServer(backend.URL, 1)
+	return srv
+}
+
+func TestGRPCProbeServing(t *testing.T) {
+	health := newHealthService(map[string]uint64{"": grpcServing})
+	srv := grpcTarget(t, health)
+
+	probe := server.NewGRPCProbe(server.GRPCProbeConfig{Timeout: time.Second})
+
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("SERVING should pass: %v", err)
+	}
+
+	health.set("", grpcNotServing)
+	err := probe.Check(context.Background(), srv)
+	if err == nil || !strings.Contains(err.Error(), "NOT_SERVING") {
+		t.Errorf("NOT_SERVING should fail, got %v", err)
+	}
+}
+
+func TestGRPCProbeService(t *testing.T) {
+	health := newHealthService(map[string]uint64{
+		"":             grpcServing,
+		"payments.v1":  grpcServing,
+		"inventory.v1": grpcNotServing,
+	})
+	srv := grpcTarget(t, health)
+
+	probe := server.NewGRPCProbe(server.GRPCProbeConfig{Service: "payments.v1"})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("payments.v1 is SERVING: %v", err)
+	}
+	if got := health.lastService(); got != "payments.v1" {
+		t.Errorf("expected service payments.v1 to be requested, got %q", got)
+	}
+
+	long := "payments.v1." + strings.Repeat("x", 200)
+	health.set(long, grpcServing)
+
+	probe = server.NewGRPCProbe(server.GRPCProbeConfig{Service: long})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("service with a multi-byte length should pass: %v", err)
+	}
+	if got := health.lastService(); got != long {
+		t.Errorf("expected the long service name to be requested, got %q", got)
+	}
+
+	probe = server.NewGRPCProbe(server.GRPCProbeConfig{Service: "inventory.v1"})
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("inventory.v1 is NOT_SERVING and should fail")
+	}
+
+	probe = server.NewGRPCProbe(server.GRPCProbeConfig{Service: "missing.v1"})
+	err := probe.Check(context.Background(), srv)
+	if err == nil || !strings.Contains(err.Error(), "unknown service") {
+		t.Errorf("unknown service should fail with the grpc message, got %v", err)
+	}
+}
+
+func TestGRPCProbeNonGRPCBackend(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	probe := server.NewGRPCProbe(server.GRPCProbeConfig{Timeout: time.Second})
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("a backend without the health service should fail")
+	}
+}
+
+func TestGRPCProbeTLS(t *testing.T) {
+	health := newHealthService(map[string]uint64{"": grpcServing})
+
+	backend := httptest.NewUnstartedServer(health)
+	backend.EnableHTTP2 = true
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	srv, _ := domain.NewServer(backend.URL, 1)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(backend.Certificate())
+
+	probe := server.NewGRPCProbe(server.GRPCProbeConfig{TLSConfig: &tls.Config{RootCAs: roots}})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("SERVING over TLS should pass: %v", err)
+	}
+
+	untrusted := server.NewGRPCProbe(server.GRPCProbeConfig{Timeout: time.Second})
+	if err := untrusted.Check(context.Background(), srv); err == nil {
+		t.Error("expected untrusted certificate to fail")
+	}
+
+	// The test certificate names example.com but not localhost.
+	viaAddress, _ := domain.NewServer("https://example.com", 1)
+	viaAddress.HealthCheckAddress = strings.Replace(backend.Listener.Addr().String(), "127.0.0.1", "localhost", 1)
+
+	if err := probe.Check(context.Background(), viaAddress); err != nil {
+		t.Errorf("certificate for the backend host should pass when dialing the check address: %v", err)
+	}
+}
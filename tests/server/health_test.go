@@ -2,14 +2,18 @@ package server_test
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"janus/internal/domain"
+	"janus/internal/metrics"
 	"janus/internal/server"
 )
 
@@ -175,3 +179,402 @@ func TestHealthCheckerMultipleServers(t *testing.T) {
 		t.Error("srv2 should not be alive")
 	}
 }
+
+func TestHealthCheckerHTTPProbeDetectsFailingApp(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer backend.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backend.URL, 1)
+	pool.AddServer(srv)
+
+	checker := server.NewHealthChecker(pool, time.Second)
+
+	checker.CheckOnce()
+	if !srv.IsAlive() {
+		t.Fatal("TCP check should pass while the port accepts connections")
+	}
+
+	probe, _ := server.NewHTTPProbe(server.HTTPProbeConfig{Path: "/health"})
+	checker.SetProbe(srv, probe)
+
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Error("HTTP check should mark a server answering 500 as down")
+	}
+}
+
+func TestHealthCheckerDefaultProbe(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer backend.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backend.URL, 1)
+	pool.AddServer(srv)
+
+	checker := server.NewHealthChecker(pool, time.Second)
+	probe, _ := server.NewHTTPProbe(server.HTTPProbeConfig{})
+	checker.SetDefaultProbe(probe)
+
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Error("default probe should apply to servers without an override")
+	}
+}
+
+type flakyBackend struct {
+	*httptest.Server
+	healthy atomic.Bool
+}
+
+func newFlakyBackend(t *testing.T) *flakyBackend {
+	t.Helper()
+
+	b := &flakyBackend{}
+	b.healthy.Store(true)
+	b.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(b.Close)
+
+	return b
+}
+
+func newThresholdChecker(t *testing.T, healthy, unhealthy int) (*server.HealthChecker, *flakyBackend, *domain.Server) {
+	t.Helper()
+
+	backend := newFlakyBackend(t)
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backend.URL, 1)
+	pool.AddServer(srv)
+
+	probe, err := server.NewHTTPProbe(server.HTTPProbeConfig{})
+	if err != nil {
+		t.Fatalf("failed to create probe: %v", err)
+	}
+
+	checker := server.NewHealthChecker(pool, time.Second)
+	checker.SetDefaultProbe(probe)
+	checker.SetThresholds(healthy, unhealthy)
+
+	return checker, backend, srv
+}
+
+func TestHealthCheckerUnhealthyThreshold(t *testing.T) {
+	checker, backend, srv := newThresholdChecker(t, 1, 3)
+
+	backend.healthy.Store(false)
+
+	for i := 1; i < 3; i++ {
+		checker.CheckOnce()
+		if !srv.IsAlive() {
+			t.Fatalf("server went DOWN after %d failures, want 3", i)
+		}
+	}
+
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Error("server should be DOWN after 3 consecutive failures")
+	}
+}
+
+func TestHealthCheckerHealthyThreshold(t *testing.T) {
+	checker, backend, srv := newThresholdChecker(t, 2, 1)
+
+	backend.healthy.Store(false)
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Fatal("server should be DOWN after one failure")
+	}
+
+	backend.healthy.Store(true)
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Fatal("one passing check should not bring the server UP")
+	}
+
+	checker.CheckOnce()
+	if !srv.IsAlive() {
+		t.Error("server should be UP after 2 consecutive passing checks")
+	}
+}
+
+func TestHealthCheckerFlappingBackendKeepsState(t *testing.T) {
+	checker, backend, srv := newThresholdChecker(t, 2, 2)
+
+	for i := 0; i < 10; i++ {
+		backend.healthy.Store(i%2 == 0)
+		checker.CheckOnce()
+
+		if !srv.IsAlive() {
+			t.Fatalf("alternating results should never reach the threshold (check %d)", i)
+		}
+	}
+
+	backend.healthy.Store(false)
+	checker.CheckOnce()
+	checker.CheckOnce()
+
+	if srv.IsAlive() {
+		t.Error("two consecutive failures should take the server DOWN")
+	}
+
+	for i := 0; i < 10; i++ {
+		backend.healthy.Store(i%2 == 1)
+		checker.CheckOnce()
+
+		if srv.IsAlive() {
+			t.Fatalf("alternating results should not bring the server back (check %d)", i)
+		}
+	}
+}
+
+func TestHealthCheckerDefaultThresholds(t *testing.T) {
+	checker, backend, srv := newThresholdChecker(t, 0, 0)
+
+	backend.healthy.Store(false)
+	checker.CheckOnce()
+	if srv.IsAlive() {
+		t.Fatal("default threshold should take the server DOWN after one failure")
+	}
+
+	backend.healthy.Store(true)
+	checker.CheckOnce()
+	if !srv.IsAlive() {
+		t.Error("default threshold should bring the server UP after one success")
+	}
+}
+
+// gatedProbe records when each server is probed and how many probes overlap.
+type gatedProbe struct {
+	delay time.Duration
+
+	mu        sync.Mutex
+	running   int
+	peak      int
+	perServer map[*domain.Server]int
+	overlap   bool
+	first     map[*domain.Server]time.Time
+	calls     int
+}
+
+func newGatedProbe(delay time.Duration) *gatedProbe {
+	return &gatedProbe{
+		delay:     delay,
+		perServer: make(map[*domain.Server]int),
+		first:     make(map[*domain.Server]time.Time),
+	}
+}
+
+func (p *gatedProbe) Check(ctx context.Context, srv *domain.Server) error {
+	p.mu.Lock()
+	p.running++
+	p.peak = max(p.peak, p.running)
+	p.perServer[srv]++
+	if p.perServer[srv] > 1 {
+		p.overlap = true
+	}
+	if _, ok := p.first[srv]; !ok {
+		p.first[srv] = time.Now()
+	}
+	p.calls++
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(p.delay):
+	}
+
+	p.mu.Lock()
+	p.running--
+	p.perServer[srv]--
+	p.mu.Unlock()
+
+	return nil
+}
+
+func newScheduledPool(n int) *domain.ServerPool {
+	pool := domain.NewServerPool()
+	for i := range n {
+		srv, _ := domain.NewServer("http://backend-"+strconv.Itoa(i)+":80", 1)
+		pool.AddServer(srv)
+	}
+	return pool
+}
+
+func TestHealthCheckerBoundedConcurrency(t *testing.T) {
+	pool := newScheduledPool(10)
+	probe := newGatedProbe(30 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := server.NewHealthChecker(pool, 20*time.Millisecond)
+	checker.SetDefaultProbe(probe)
+	checker.SetConcurrency(3)
+	checker.Start(ctx)
+
+	time.Sleep(300 * time.Millisecond)
+	cancel()
+
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	if probe.peak > 3 {
+		t.Errorf("expected at most 3 concurrent probes, got %d", probe.peak)
+	}
+	if probe.calls < 10 {
+		t.Errorf("expected every server to be probed, got %d probes", probe.calls)
+	}
+}
+
+func TestHealthCheckerOneProbePerServer(t *testing.T) {
+	pool := newScheduledPool(2)
+	probe := newGatedProbe(60 * time.Millisecond)
+	registry := metrics.NewRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := server.NewHealthChecker(pool, 10*time.Millisecond)
+	checker.SetDefaultProbe(probe)
+	checker.Instrument(registry)
+	checker.Start(ctx)
+
+	time.Sleep(250 * time.Millisecond)
+	cancel()
+
+	probe.mu.Lock()
+	overlap := probe.overlap
+	probe.mu.Unlock()
+
+	if overlap {
+		t.Error("a server must never have two probes in flight")
+	}
+
+	srv := pool.GetServers()[0]
+	skipped := registry.Counter("janus_health_checks_missed_total", "",
+		"server", srv.URL.String(), "reason", "skipped")
+	if skipped.Value() == 0 {
+		t.Error("probes slower than the interval should count skipped checks")
+	}
+}
+
+func TestHealthCheckerLateProbes(t *testing.T) {
+	pool := newScheduledPool(4)
+	probe := newGatedProbe(100 * time.Millisecond)
+	registry := metrics.NewRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := server.NewHealthChecker(pool, 50*time.Millisecond)
+	checker.SetDefaultProbe(probe)
+	checker.SetConcurrency(1)
+	checker.Instrument(registry)
+	checker.Start(ctx)
+
+	time.Sleep(350 * time.Millisecond)
+	cancel()
+
+	var late uint64
+	for _, srv := range pool.GetServers() {
+		late += registry.Counter("janus_health_checks_missed_total", "",
+			"server", srv.URL.String(), "reason", "late").Value()
+	}
+	if late == 0 {
+		t.Error("probes waiting for a worker should be counted as late")
+	}
+}
+
+func TestHealthCheckerJitterSpreadsProbes(t *testing.T) {
+	pool := newScheduledPool(20)
+	probe := newGatedProbe(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := server.NewHealthChecker(pool, time.Second)
+	checker.SetDefaultProbe(probe)
+	checker.SetJitter(0.5)
+	checker.Start(ctx)
+
+	time.Sleep(600 * time.Millisecond)
+	cancel()
+
+	probe.mu.Lock()
+	defer probe.mu.Unlock()
+
+	if len(probe.first) != 20 {
+		t.Fatalf("expected every server to be probed within the jitter window, got %d", len(probe.first))
+	}
+
+	var earliest, latest time.Time
+	for _, at := range probe.first {
+		if earliest.IsZero() || at.Before(earliest) {
+			earliest = at
+		}
+		if at.After(latest) {
+			latest = at
+		}
+	}
+
+	if spread := latest.Sub(earliest); spread < 100*time.Millisecond {
+		t.Errorf("expected first probes to be spread out, all landed within %v", spread)
+	}
+}
+
+func TestHealthCheckerRecordsHistory(t *testing.T) {
+	checker, backend, srv := newThresholdChecker(t, 1, 1)
+
+	checker.CheckOnce()
+	backend.healthy.Store(false)
+	checker.CheckOnce()
+
+	history := srv.HealthHistory()
+	if len(history) != 2 {
+		t.Fatalf("history length = %d, want 2", len(history))
+	}
+
+	if !history[0].Passed || history[0].Error != "" {
+		t.Errorf("first check = %+v, want a pass", history[0])
+	}
+	if history[1].Passed || history[1].Error == "" {
+		t.Errorf("second check = %+v, want a failure with its error", history[1])
+	}
+	if history[1].Time.Before(history[0].Time) || history[0].Latency <= 0 {
+		t.Errorf("records should carry start time and latency: %+v", history)
+	}
+}
+
+type warningProbe struct{}
+
+func (warningProbe) Check(context.Context, *domain.Server) error {
+	return &server.ProbeWarning{Err: errors.New("certificate expires in 5 days")}
+}
+
+func TestHealthCheckerTreatsWarningsAsPassing(t *testing.T) {
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer("https://localhost:8443", 1)
+	srv.SetAlive(false)
+	pool.AddServer(srv)
+
+	checker := server.NewHealthChecker(pool, time.Second)
+	checker.SetDefaultProbe(warningProbe{})
+	checker.CheckOnce()
+
+	if !srv.IsAlive() {
+		t.Error("a probe warning should count as a passed check")
+	}
+
+	history := srv.HealthHistory()
+	if len(history) != 1 || !history[0].Passed || history[0].Warning != "certificate expires in 5 days" {
+		t.Errorf("history = %+v, want a pass with the warning", history)
+	}
+}
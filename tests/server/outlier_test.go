@@ -0,0 +1,258 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+var (
+	serverError = balancer.Result{StatusCode: http.StatusInternalServerError}
+	success     = balancer.Result{StatusCode: http.StatusOK}
+	gatewayErr  = balancer.Result{StatusCode: http.StatusBadGateway, Err: errors.New("connection refused")}
+)
+
+func newOutlierPool(count int) (*domain.ServerPool, []*domain.Server) {
+	pool := domain.NewServerPool()
+	servers := make([]*domain.Server, count)
+
+	for i := range servers {
+		servers[i], _ = domain.NewServer("http://localhost:"+strconv.Itoa(8081+i), 1)
+		pool.AddServer(servers[i])
+	}
+
+	return pool, servers
+}
+
+func report(d *server.OutlierDetector, s *domain.Server, result balancer.Result, times int) {
+	for i := 0; i < times; i++ {
+		d.Report(s, result)
+	}
+}
+
+func TestOutlierDetectorEjectsAfterConsecutive5xx(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{Consecutive5xx: 3, MaxEjectionPercent: 50})
+
+	report(d, servers[0], serverError, 2)
+	if servers[0].IsEjected() {
+		t.Fatal("server should not be ejected before the threshold")
+	}
+
+	d.Report(servers[0], serverError)
+	if !servers[0].IsEjected() {
+		t.Fatal("server should be ejected after 3 consecutive 5xx")
+	}
+
+	healthy := pool.GetHealthyServers()
+	if len(healthy) != 1 || healthy[0] != servers[1] {
+		t.Error("ejected server should leave the healthy set immediately")
+	}
+
+	if !servers[0].IsAlive() {
+		t.Error("ejection must not change the active health state")
+	}
+}
+
+func TestOutlierDetectorSuccessResetsStreak(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{Consecutive5xx: 3})
+
+	report(d, servers[0], serverError, 2)
+	d.Report(servers[0], success)
+	report(d, servers[0], serverError, 2)
+
+	if servers[0].IsEjected() {
+		t.Error("a success in between should reset the consecutive count")
+	}
+}
+
+func TestOutlierDetectorGatewayErrors(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{Consecutive5xx: 10, ConsecutiveGatewayErrors: 2})
+
+	d.Report(servers[0], gatewayErr)
+	d.Report(servers[0], serverError)
+	d.Report(servers[0], gatewayErr)
+	if servers[0].IsEjected() {
+		t.Fatal("a plain 500 should break the gateway error streak")
+	}
+
+	d.Report(servers[0], balancer.Result{StatusCode: http.StatusGatewayTimeout})
+	if !servers[0].IsEjected() {
+		t.Error("server should be ejected after 2 consecutive gateway errors")
+	}
+}
+
+func TestOutlierDetectorEjectionExpires(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{
+		Consecutive5xx:   1,
+		BaseEjectionTime: 50 * time.Millisecond,
+	})
+
+	d.Report(servers[0], serverError)
+	if !servers[0].IsEjected() {
+		t.Fatal("server should be ejected")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if servers[0].IsEjected() || len(pool.GetHealthyServers()) != 2 {
+		t.Error("server should return once the ejection time has passed")
+	}
+}
+
+func TestOutlierDetectorBacksOffExponentially(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{
+		Consecutive5xx:   1,
+		BaseEjectionTime: 40 * time.Millisecond,
+		MaxEjectionTime:  time.Second,
+	})
+
+	d.Report(servers[0], serverError)
+	first := time.Until(servers[0].EjectedUntil())
+
+	time.Sleep(60 * time.Millisecond)
+	if servers[0].IsEjected() {
+		t.Fatal("first ejection should have expired")
+	}
+
+	d.Report(servers[0], serverError)
+	second := time.Until(servers[0].EjectedUntil())
+
+	if second < first+first/2 {
+		t.Errorf("repeat ejection lasted %v, want about twice %v", second, first)
+	}
+}
+
+func TestOutlierDetectorMaxEjectionPercent(t *testing.T) {
+	pool, servers := newOutlierPool(4)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{Consecutive5xx: 1, MaxEjectionPercent: 25})
+
+	d.Report(servers[0], serverError)
+	d.Report(servers[1], serverError)
+
+	if !servers[0].IsEjected() || servers[1].IsEjected() {
+		t.Error("only 25% of the pool may be ejected at once")
+	}
+
+	if pool.EjectedCount() != 1 {
+		t.Errorf("ejected count = %d, want 1", pool.EjectedCount())
+	}
+}
+
+func TestOutlierDetectorAlwaysAllowsOneEjection(t *testing.T) {
+	pool, servers := newOutlierPool(2)
+	d := server.NewOutlierDetector(pool, server.OutlierConfig{Consecutive5xx: 1, MaxEjectionPercent: 10})
+
+	d.Report(servers[0], serverError)
+
+	if !servers[0].IsEjected() {
+		t.Error("a single ejection should be allowed even above the percentage")
+	}
+}
+
+func newEjectedBackend(t *testing.T, cfg server.OutlierConfig) (*server.HealthChecker, *server.OutlierDetector, *domain.Server) {
+	t.Helper()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(backend.Close)
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backend.URL, 1)
+	pool.AddServer(srv)
+
+	d := server.NewOutlierDetector(pool, cfg)
+	d.Report(srv, serverError)
+
+	if !srv.IsEjected() {
+		t.Fatal("server should be ejected")
+	}
+
+	checker := server.NewHealthChecker(pool, time.Second)
+	checker.SetOutlierDetector(d)
+
+	return checker, d, srv
+}
+
+func TestHealthCheckKeepsEjectionByDefault(t *testing.T) {
+	checker, _, srv := newEjectedBackend(t, server.OutlierConfig{Consecutive5xx: 1, BaseEjectionTime: time.Hour})
+
+	checker.CheckOnce()
+
+	if !srv.IsEjected() {
+		t.Error("a passing TCP check must not cut the ejection time short")
+	}
+}
+
+func TestHealthCheckEndsEjectionWhenEnabled(t *testing.T) {
+	checker, d, srv := newEjectedBackend(t, server.OutlierConfig{
+		Consecutive5xx:       1,
+		BaseEjectionTime:     time.Hour,
+		MaxEjectionTime:      8 * time.Hour,
+		UnejectOnHealthCheck: true,
+	})
+
+	checker.CheckOnce()
+
+	if srv.IsEjected() {
+		t.Fatal("a passing active health check should end the ejection when enabled")
+	}
+
+	// The ejection counts as over now, so the next one still backs off.
+	d.Report(srv, serverError)
+
+	remaining := time.Until(srv.EjectedUntil())
+	if remaining < 119*time.Minute || remaining > 2*time.Hour {
+		t.Errorf("second ejection lasts %v, want 2h", remaining.Round(time.Minute))
+	}
+}
+
+func TestProxyHandlerEjectsFailingBackend(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer good.Close()
+
+	pool := domain.NewServerPool()
+	dead, _ := domain.NewServer("http://localhost:59997", 1)
+	alive, _ := domain.NewServer(good.URL, 1)
+	pool.AddServer(dead)
+	pool.AddServer(alive)
+
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+	handler.SetOutlierDetector(server.NewOutlierDetector(pool, server.OutlierConfig{
+		Consecutive5xx:     2,
+		BaseEjectionTime:   time.Hour,
+		MaxEjectionPercent: 50,
+	}))
+
+	codes := make([]int, 0, 10)
+	for i := 0; i < 10; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes = append(codes, rec.Code)
+	}
+
+	failures := 0
+	for _, code := range codes {
+		if code == http.StatusBadGateway {
+			failures++
+		}
+	}
+
+	if failures != 2 {
+		t.Errorf("got %d gateway errors (%v), want 2 before the dead backend is ejected", failures, codes)
+	}
+
+	if !dead.IsEjected() || !dead.IsAlive() {
+		t.Error("gateway errors should eject the backend without touching its health state")
+	}
+}
@@ -0,0 +1,232 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+func probeTarget(t *testing.T, handler http.HandlerFunc) *domain.Server {
+	t.Helper()
+
+	backend := httptest.NewServer(handler)
+	t.Cleanup(backend.Close)
+
+	srv, _ := domain.NewServer(backend.URL, 1)
+	return srv
+}
+
+func newHTTPProbe(t *testing.T, cfg server.HTTPProbeConfig) *server.HTTPProbe {
+	t.Helper()
+
+	probe, err := server.NewHTTPProbe(cfg)
+	if err != nil {
+		t.Fatalf("failed to create probe: %v", err)
+	}
+	return probe
+}
+
+func TestTCPProbe(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {})
+	probe := server.NewTCPProbe(time.Second)
+
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("expected listening server to pass: %v", err)
+	}
+
+	down, _ := domain.NewServer("http://localhost:59999", 1)
+	if err := probe.Check(context.Background(), down); err == nil {
+		t.Error("expected closed port to fail")
+	}
+}
+
+func TestHTTPProbeStatus(t *testing.T) {
+	status := http.StatusOK
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{})
+
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("200 should pass by default: %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("500 should fail by default")
+	}
+
+	status = http.StatusFound
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("redirects should not be followed or accepted by default")
+	}
+}
+
+func TestHTTPProbeExpectedStatusRanges(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{ExpectedStatus: []string{"200-299", "503"}})
+
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("503 is listed as expected: %v", err)
+	}
+}
+
+func TestHTTPProbeRequest(t *testing.T) {
+	var got *http.Request
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+	})
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{
+		Path:    "/healthz?deep=1",
+		Method:  http.MethodHead,
+		Headers: map[string]string{"X-Probe": "janus"},
+		Host:    "app.internal",
+	})
+
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Method != http.MethodHead || got.URL.Path != "/healthz" || got.URL.RawQuery != "deep=1" {
+		t.Errorf("request = %s %s, want HEAD /healthz?deep=1", got.Method, got.URL)
+	}
+	if got.Host != "app.internal" {
+		t.Errorf("host = %s, want app.internal", got.Host)
+	}
+	if got.Header.Get("X-Probe") != "janus" {
+		t.Error("configured header should be sent")
+	}
+}
+
+func TestHTTPProbeBodyMatch(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "degraded", "db": "ok"}`))
+	})
+
+	tests := []struct {
+		name    string
+		cfg     server.HTTPProbeConfig
+		wantErr bool
+	}{
+		{"substring match", server.HTTPProbeConfig{Body: `"db": "ok"`}, false},
+		{"substring mismatch", server.HTTPProbeConfig{Body: `"status": "ok"`}, true},
+		{"regex match", server.HTTPProbeConfig{BodyRegex: `"status":\s*"(ok|degraded)"`}, false},
+		{"regex mismatch", server.HTTPProbeConfig{BodyRegex: `^ok$`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newHTTPProbe(t, tt.cfg).Check(context.Background(), srv)
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeTimeout(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("expected timeout error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("probe should give up after its timeout")
+	}
+}
+
+func TestHTTPProbeInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  server.HTTPProbeConfig
+	}{
+		{"relative path", server.HTTPProbeConfig{Path: "health"}},
+		{"invalid method", server.HTTPProbeConfig{Method: "GET /"}},
+		{"invalid status", server.HTTPProbeConfig{ExpectedStatus: []string{"abc"}}},
+		{"reversed range", server.HTTPProbeConfig{ExpectedStatus: []string{"299-200"}}},
+		{"status out of range", server.HTTPProbeConfig{ExpectedStatus: []string{"700"}}},
+		{"invalid regex", server.HTTPProbeConfig{BodyRegex: "("}},
+		{"body and regex", server.HTTPProbeConfig{Body: "ok", BodyRegex: "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err == nil {
+				t.Error("expected validation error, got nil")
+			}
+			if _, err := server.NewHTTPProbe(tt.cfg); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestProbesUseHealthCheckAddress(t *testing.T) {
+	srv := probeTarget(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	management := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer management.Close()
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{Path: "/healthz"})
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Fatal("traffic port does not serve /healthz and should fail")
+	}
+
+	srv.HealthCheckAddress = management.Listener.Addr().String()
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("probe should reach /healthz on the management port: %v", err)
+	}
+
+	srv.HealthCheckAddress = "localhost:59999"
+	if err := server.NewTCPProbe(time.Second).Check(context.Background(), srv); err == nil {
+		t.Error("TCP probe should dial the health check address, not the URL host")
+	}
+}
+
+func TestHTTPProbeKeepsBackendHostWithAddress(t *testing.T) {
+	var host string
+	management := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer management.Close()
+
+	srv, _ := domain.NewServer("http://api.internal:8080", 1)
+	srv.HealthCheckAddress = management.Listener.Addr().String()
+
+	probe := newHTTPProbe(t, server.HTTPProbeConfig{})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Fatalf("probe should reach the health check address: %v", err)
+	}
+
+	if host != "api.internal:8080" {
+		t.Errorf("Host = %q, want the backend host api.internal:8080", host)
+	}
+}
@@ -1,10 +1,12 @@
 package server_test
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"janus/internal/balancer"
 	"janus/internal/domain"
@@ -178,6 +180,32 @@ func TestProxyHandlerConnectionCount(t *testing.T) {
 	}
 }
 
+func TestProxyHandlerRecordsLatency(t *testing.T) {
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backendServer.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backendServer.URL, 1)
+	pool.AddServer(srv)
+
+	handler := server.NewProxyHandler(pool, balancer.NewLeastLatency())
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	latency, ok := srv.LatencyEWMA()
+	if !ok {
+		t.Fatal("proxy should record backend latency")
+	}
+	if latency < 15*time.Millisecond {
+		t.Errorf("recorded latency = %v, want at least ~20ms", latency)
+	}
+}
+
 func TestProxyHandlerRoundRobinDistribution(t *testing.T) {
 	requestCounts := make(map[string]int)
 
@@ -218,3 +246,306 @@ func TestProxyHandlerRoundRobinDistribution(t *testing.T) {
 		t.Errorf("server2 got %d requests, want 5", requestCounts["server2"])
 	}
 }
+
+func TestProxyHandlerPassesRequestToStrategy(t *testing.T) {
+	backends := make([]*httptest.Server, 3)
+	pool := domain.NewServerPool()
+
+	for i := range backends {
+		name := "server" + string(rune('1'+i))
+		backends[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name))
+		}))
+		defer backends[i].Close()
+
+		srv, _ := domain.NewServer(backends[i].URL, 1)
+		pool.AddServer(srv)
+	}
+
+	keys, _ := balancer.NewHashKeyExtractor(balancer.HashKeyHeader, "X-User-ID")
+	handler := server.NewProxyHandler(pool, balancer.NewConsistentHash(keys, balancer.DefaultVirtualNodes))
+
+	var first string
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-User-ID", "alice")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		body, _ := io.ReadAll(rec.Body)
+		if first == "" {
+			first = string(body)
+		}
+		if string(body) != first {
+			t.Fatalf("request %d went to %s, want %s", i, body, first)
+		}
+	}
+}
+
+type recordingStrategy struct {
+	balancer.Strategy
+	results []balancer.Result
+}
+
+func (s *recordingStrategy) Done(server *domain.Server, r *http.Request, result balancer.Result) {
+	s.results = append(s.results, result)
+}
+
+func TestProxyHandlerReportsResultToObserver(t *testing.T) {
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer backendServer.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backendServer.URL, 1)
+	pool.AddServer(srv)
+
+	strategy := &recordingStrategy{Strategy: balancer.NewRoundRobin()}
+	handler := server.NewProxyHandler(pool, strategy)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(strategy.results) != 1 {
+		t.Fatalf("observer called %d times, want 1", len(strategy.results))
+	}
+
+	result := strategy.results[0]
+	if result.StatusCode != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", result.StatusCode, http.StatusTeapot)
+	}
+	if result.Err != nil {
+		t.Errorf("unexpected error: %v", result.Err)
+	}
+	if result.Latency <= 0 {
+		t.Error("latency should be recorded")
+	}
+	if result.Failed() {
+		t.Error("4xx response should not count as a failure")
+	}
+}
+
+func TestProxyHandlerReportsGatewayError(t *testing.T) {
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer("http://localhost:59998", 1)
+	pool.AddServer(srv)
+
+	strategy := &recordingStrategy{Strategy: balancer.NewRoundRobin()}
+	handler := server.NewProxyHandler(pool, strategy)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+
+	if len(strategy.results) != 1 {
+		t.Fatalf("observer called %d times, want 1", len(strategy.results))
+	}
+
+	result := strategy.results[0]
+	if result.Err == nil || !result.Failed() {
+		t.Error("connection failure should be reported as a failed result")
+	}
+}
+
+func TestProxyHandlerSkipsOpenCircuit(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+
+	pool := domain.NewServerPool()
+	bad, _ := domain.NewServer(failing.URL, 1)
+	good, _ := domain.NewServer(healthy.URL, 1)
+	for _, s := range []*domain.Server{bad, good} {
+		s.SetCircuitBreaker(domain.BreakerConfig{
+			ConsecutiveFailures: 2,
+			Window:              time.Minute,
+			OpenTimeout:         time.Hour,
+			HalfOpenRequests:    1,
+		})
+		pool.AddServer(s)
+	}
+
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+
+	failures := 0
+	for i := 0; i < 20; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			failures++
+		}
+	}
+
+	if failures != 2 {
+		t.Errorf("got %d failed responses, want 2 before the circuit opens", failures)
+	}
+
+	if bad.BreakerState() != domain.BreakerOpen || !bad.IsAlive() {
+		t.Error("failing server should have an open circuit and keep its health state")
+	}
+}
+
+func TestProxyHandlerPanicModeSkipsOpenCircuits(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+
+	pool := domain.NewServerPool()
+	pool.SetPanicThreshold(0.5)
+
+	for i := 0; i < 10; i++ {
+		target := healthy.URL
+		if i >= 2 {
+			target = failing.URL
+		}
+
+		s, _ := domain.NewServer(target, 1)
+		s.SetCircuitBreaker(domain.BreakerConfig{
+			ConsecutiveFailures: 1,
+			Window:              time.Minute,
+			OpenTimeout:         time.Hour,
+			HalfOpenRequests:    1,
+		})
+		pool.AddServer(s)
+
+		if i >= 2 {
+			s.RecordResult(false)
+		}
+	}
+
+	if !pool.InPanic() {
+		t.Fatal("pool should be in panic mode with 8 of 10 circuits open")
+	}
+
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+
+	failures := 0
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			failures++
+		}
+	}
+
+	if failures != 0 {
+		t.Errorf("got %d failed responses, want panic mode to skip open circuits", failures)
+	}
+}
+
+func TestProxyHandlerRespectsHalfOpenTrials(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(slow.URL, 1)
+	srv.SetCircuitBreaker(domain.BreakerConfig{
+		ConsecutiveFailures: 1,
+		Window:              time.Minute,
+		OpenTimeout:         10 * time.Millisecond,
+		HalfOpenRequests:    1,
+	})
+	pool.AddServer(srv)
+
+	srv.AllowRequest()
+	srv.RecordResult(false)
+
+	deadline := time.Now().Add(time.Second)
+	for srv.BreakerState() != domain.BreakerHalfOpen {
+		if time.Now().After(deadline) {
+			t.Fatal("circuit did not become half-open")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- rec.Code
+	}()
+
+	for srv.GetConnections() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("second request status = %d, want %d while the trial is in flight", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	close(release)
+
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("trial request status = %d, want %d", code, http.StatusOK)
+	}
+	if srv.BreakerState() != domain.BreakerClosed {
+		t.Errorf("state = %s, want closed after a successful trial", srv.BreakerState())
+	}
+}
+
+func TestProxyHandlerIgnoresClientCancellation(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer backend.Close()
+
+	pool := domain.NewServerPool()
+	srv, _ := domain.NewServer(backend.URL, 1)
+	srv.SetCircuitBreaker(domain.BreakerConfig{
+		ConsecutiveFailures: 1,
+		Window:              time.Minute,
+		OpenTimeout:         time.Hour,
+		HalfOpenRequests:    1,
+	})
+	pool.AddServer(srv)
+
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+	handler.SetOutlierDetector(server.NewOutlierDetector(pool, server.OutlierConfig{
+		Consecutive5xx:     1,
+		BaseEjectionTime:   time.Hour,
+		MaxEjectionPercent: 100,
+	}))
+
+	for range 3 {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+
+		for srv.GetConnections() == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		cancel()
+		<-done
+	}
+
+	if srv.BreakerState() != domain.BreakerClosed {
+		t.Errorf("state = %s, client cancellations must not open the circuit", srv.BreakerState())
+	}
+	if srv.IsEjected() {
+		t.Error("client cancellations must not eject the backend")
+	}
+}
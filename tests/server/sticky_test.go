@@ -0,0 +1,172 @@
+package server_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"janus/internal/balancer"
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+var stickyKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newStickyPool(t *testing.T, names ...string) (*domain.ServerPool, []*domain.Server) {
+	t.Helper()
+
+	pool := domain.NewServerPool()
+	servers := make([]*domain.Server, len(names))
+
+	for i, name := range names {
+		body := name
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		t.Cleanup(backend.Close)
+
+		servers[i], _ = domain.NewServer(backend.URL, 1)
+		pool.AddServer(servers[i])
+	}
+
+	return pool, servers
+}
+
+func doRequest(handler http.Handler, cookie *http.Cookie) (string, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body, _ := io.ReadAll(rec.Body)
+
+	var issued *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "janus_server" {
+			issued = c
+		}
+	}
+
+	return string(body), issued
+}
+
+func newStickyHandler(pool *domain.ServerPool) *server.ProxyHandler {
+	handler := server.NewProxyHandler(pool, balancer.NewRoundRobin())
+	handler.SetStickySessions(server.NewStickySessions("janus_server", time.Hour, stickyKey))
+	return handler
+}
+
+func TestStickySessionsPinsToFirstBackend(t *testing.T) {
+	pool, _ := newStickyPool(t, "server1", "server2", "server3")
+	handler := newStickyHandler(pool)
+
+	first, cookie := doRequest(handler, nil)
+	if cookie == nil {
+		t.Fatal("first response should set the sticky cookie")
+	}
+
+	for i := 0; i < 6; i++ {
+		body, reissued := doRequest(handler, cookie)
+		if body != first {
+			t.Fatalf("request %d went to %s, want %s", i, body, first)
+		}
+		if reissued != nil {
+			t.Error("cookie should not be reissued while the backend is healthy")
+		}
+	}
+}
+
+func TestStickySessionsCookieAttributes(t *testing.T) {
+	pool, _ := newStickyPool(t, "server1")
+	handler := newStickyHandler(pool)
+
+	_, cookie := doRequest(handler, nil)
+	if cookie == nil {
+		t.Fatal("expected sticky cookie")
+	}
+
+	if !cookie.HttpOnly {
+		t.Error("sticky cookie should be HttpOnly")
+	}
+	if cookie.MaxAge != 3600 {
+		t.Errorf("MaxAge = %d, want 3600", cookie.MaxAge)
+	}
+	if cookie.Path != "/" {
+		t.Errorf("Path = %s, want /", cookie.Path)
+	}
+}
+
+func TestStickySessionsFallsBackWhenUnhealthy(t *testing.T) {
+	pool, servers := newStickyPool(t, "server1", "server2")
+	handler := newStickyHandler(pool)
+
+	first, cookie := doRequest(handler, nil)
+
+	for i, s := range servers {
+		if "server"+string(rune('1'+i)) == first {
+			pool.SetServerStatus(s, false)
+		}
+	}
+
+	body, reissued := doRequest(handler, cookie)
+	if body == first {
+		t.Fatal("request should not go to an unhealthy pinned backend")
+	}
+	if reissued == nil {
+		t.Fatal("fallback response should pin the client to the new backend")
+	}
+
+	again, _ := doRequest(handler, reissued)
+	if again != body {
+		t.Errorf("request went to %s, want new pinned backend %s", again, body)
+	}
+}
+
+func TestStickySessionsIgnoresTamperedCookie(t *testing.T) {
+	pool, _ := newStickyPool(t, "server1", "server2")
+	handler := newStickyHandler(pool)
+
+	_, cookie := doRequest(handler, nil)
+
+	tampered := &http.Cookie{Name: cookie.Name, Value: "ffffffffffffffff" + cookie.Value[16:]}
+	_, reissued := doRequest(handler, tampered)
+	if reissued == nil {
+		t.Error("tampered cookie should be ignored and replaced")
+	}
+
+	garbage := &http.Cookie{Name: cookie.Name, Value: "not-a-session"}
+	_, reissued = doRequest(handler, garbage)
+	if reissued == nil {
+		t.Error("malformed cookie should be ignored and replaced")
+	}
+}
+
+func TestStickySessionsLookup(t *testing.T) {
+	pool, servers := newStickyPool(t, "server1", "server2")
+	sticky := server.NewStickySessions("janus_server", time.Hour, stickyKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(sticky.Cookie(servers[1], false))
+
+	if got := sticky.Lookup(req, pool); got != servers[1] {
+		t.Errorf("lookup = %v, want %s", got, servers[1].URL)
+	}
+
+	other := server.NewStickySessions("janus_server", time.Hour, []byte("another-signing-key-entirely"))
+	if got := other.Lookup(req, pool); got != nil {
+		t.Error("cookie signed with a different key should be ignored")
+	}
+
+	expired := server.NewStickySessions("janus_server", -time.Second, stickyKey)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(expired.Cookie(servers[1], false))
+
+	if got := sticky.Lookup(req, pool); got != nil {
+		t.Error("expired cookie should be ignored")
+	}
+}
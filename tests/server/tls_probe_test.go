@@ -0,0 +1,211 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"janus/internal/domain"
+	"janus/internal/server"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "janus test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// serve starts a TLS listener with a certificate for backend.internal and
+// 127.0.0.1 that expires after the given time.
+func (ca *testCA) serve(t *testing.T, expiresIn time.Duration) *domain.Server {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "backend.internal"},
+		DNSNames:     []string{"backend.internal"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(expiresIn),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+
+	srv, _ := domain.NewServer("https://"+listener.Addr().String(), 1)
+	return srv
+}
+
+func TestLoadCertPool(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0644); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+
+	pool, err := server.LoadCertPool(caFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !pool.Equal(ca.pool) {
+		t.Error("pool should hold the CA from the file")
+	}
+
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := server.LoadCertPool(notPEM); err == nil {
+		t.Error("expected error for a file without certificates")
+	}
+
+	if _, err := server.LoadCertPool(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestTLSProbeVerifiesChain(t *testing.T) {
+	ca := newTestCA(t)
+	srv := ca.serve(t, 90*24*time.Hour)
+
+	trusted := server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, Timeout: time.Second})
+	if err := trusted.Check(context.Background(), srv); err != nil {
+		t.Errorf("certificate signed by the configured CA should pass: %v", err)
+	}
+
+	untrusted := server.NewTLSProbe(server.TLSProbeConfig{Timeout: time.Second})
+	if err := untrusted.Check(context.Background(), srv); err == nil {
+		t.Error("certificate from an unknown CA should fail")
+	}
+}
+
+func TestTLSProbeServerName(t *testing.T) {
+	ca := newTestCA(t)
+	srv := ca.serve(t, 90*24*time.Hour)
+
+	probe := server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, ServerName: "backend.internal"})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("matching server name should pass: %v", err)
+	}
+
+	probe = server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, ServerName: "other.internal"})
+	if err := probe.Check(context.Background(), srv); err == nil {
+		t.Error("mismatched server name should fail")
+	}
+}
+
+func TestTLSProbeVerifiesBackendHostWithAddress(t *testing.T) {
+	ca := newTestCA(t)
+	_, port, _ := net.SplitHostPort(ca.serve(t, 90*24*time.Hour).URL.Host)
+
+	// The certificate names backend.internal but not localhost, so the
+	// check passes only if the backend host is used for verification.
+	srv, _ := domain.NewServer("https://backend.internal", 1)
+	srv.HealthCheckAddress = net.JoinHostPort("localhost", port)
+
+	probe := server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, Timeout: time.Second})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("certificate for the backend host should pass when dialing the check address: %v", err)
+	}
+}
+
+func TestTLSProbeCertificateExpiry(t *testing.T) {
+	ca := newTestCA(t)
+	srv := ca.serve(t, 10*24*time.Hour)
+
+	day := 24 * time.Hour
+
+	probe := server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, ExpiryWarning: 30 * day, ExpiryFailure: 7 * day})
+	err := probe.Check(context.Background(), srv)
+
+	var warning *server.ProbeWarning
+	if !errors.As(err, &warning) {
+		t.Errorf("certificate expiring within the warning window should warn, got %v", err)
+	}
+
+	probe = server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, ExpiryWarning: 30 * day, ExpiryFailure: 14 * day})
+	err = probe.Check(context.Background(), srv)
+	if err == nil || errors.As(err, &warning) {
+		t.Errorf("certificate expiring within the failure window should fail, got %v", err)
+	}
+
+	probe = server.NewTLSProbe(server.TLSProbeConfig{RootCAs: ca.pool, ExpiryWarning: 5 * day})
+	if err := probe.Check(context.Background(), srv); err != nil {
+		t.Errorf("certificate outside both windows should pass: %v", err)
+	}
+}